@@ -0,0 +1,57 @@
+package sieve
+
+// Totient returns Euler's φ(n), the count of 1 <= k <= n coprime to n
+// (http://oeis.org/A000010), or 0 if n is too big for the sieve to factor.
+func (sieve *Sieve) Totient(n int) int {
+	if n < 1 || !sieve.reaches(n) { // too big for sieve?
+		return 0
+	}
+	phi := n
+	for _, f := range sieve.FactorUnique(n) {
+		if f.Factor > 1 {
+			phi = phi / f.Factor * (f.Factor - 1)
+		}
+	}
+	return phi
+}
+
+// Mobius returns the Möbius function μ(n): 0 if n has a repeated prime factor, and
+// otherwise 1 or -1 as n has an even or odd number of prime factors
+// (http://oeis.org/A008683). It also returns 0 if n is too big for the sieve to factor.
+func (sieve *Sieve) Mobius(n int) int {
+	if n < 1 || !sieve.reaches(n) { // too big for sieve?
+		return 0
+	}
+	mu := 1
+	for _, f := range sieve.FactorUnique(n) {
+		switch {
+		case f.Factor == 1:
+		case f.Count > 1:
+			return 0
+		default:
+			mu = -mu
+		}
+	}
+	return mu
+}
+
+// SumDivisors returns σ(n), the sum of the divisors of n (http://oeis.org/A000203),
+// or 0 if n is too big for the sieve to factor.
+// SumDivisors(6) == 12, from 1 + 2 + 3 + 6
+func (sieve *Sieve) SumDivisors(n int) int {
+	if n < 1 || !sieve.reaches(n) { // too big for sieve?
+		return 0
+	}
+	sigma := 1
+	for _, f := range sieve.FactorUnique(n) {
+		if f.Factor > 1 {
+			sum, power := 1, 1
+			for i := 0; i < f.Count; i++ {
+				power *= f.Factor
+				sum += power
+			}
+			sigma *= sum // σ is multiplicative: σ(p^k) = 1 + p + ... + p^k
+		}
+	}
+	return sigma
+}
@@ -0,0 +1,32 @@
+package sieve
+
+import "testing"
+
+var arithTests = []struct {
+	n, totient, mobius, sigma int
+}{
+	{0, 0, 0, 0},
+	{1, 1, 1, 1},
+	{2, 1, -1, 3},
+	{4, 2, 0, 7},
+	{6, 2, 1, 12},
+	{30, 8, -1, 72},
+	{97, 96, -1, 98},
+	{360, 96, 0, 1170},
+	{9973 * 9967, 9972 * 9966, 1, 9974 * 9968},
+}
+
+func TestArith(t *testing.T) {
+	sieve := New(10000)
+	for i, a := range arithTests {
+		if v := sieve.Totient(a.n); v != a.totient {
+			t.Errorf("#%d, Totient(%d) = %d; want %d", i, a.n, v, a.totient)
+		}
+		if v := sieve.Mobius(a.n); v != a.mobius {
+			t.Errorf("#%d, Mobius(%d) = %d; want %d", i, a.n, v, a.mobius)
+		}
+		if v := sieve.SumDivisors(a.n); v != a.sigma {
+			t.Errorf("#%d, SumDivisors(%d) = %d; want %d", i, a.n, v, a.sigma)
+		}
+	}
+}
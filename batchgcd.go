@@ -0,0 +1,116 @@
+package sieve
+
+import "math/big"
+
+// Bernstein's batch algorithms (2004) answer a question about each of many numbers with
+// a few big multiplications and divisions instead of one computation per number. Both
+// rest on the product tree, whose leaves are the numbers and whose every node is the
+// product of its two children, and the remainder tree, which reduces a value modulo the
+// root and then each node's remainder modulo its children, down to the leaves.
+
+// productTree returns the levels of the product tree of values: the values themselves,
+// then their products in pairs, and so on up to a last level holding only the product of
+// all. An odd node at the end of a level is carried up unchanged.
+func productTree(values []*big.Int) [][]*big.Int {
+	tree := [][]*big.Int{values}
+	for level := values; len(level) > 1; {
+		next := make([]*big.Int, (len(level)+1)/2)
+		for i := range next {
+			if 2*i+1 < len(level) {
+				next[i] = new(big.Int).Mul(level[2*i], level[2*i+1])
+			} else {
+				next[i] = level[2*i]
+			}
+		}
+		tree = append(tree, next)
+		level = next
+	}
+	return tree
+}
+
+// remainders returns n mod v for each leaf v of the product tree, or n mod v² if squared
+// is set, descending the tree so that each division is by a node's own (squared) value.
+func remainders(n *big.Int, tree [][]*big.Int, squared bool) []*big.Int {
+	var m big.Int
+	mod := func(x, v *big.Int) *big.Int {
+		if squared {
+			v = m.Mul(v, v)
+		}
+		return new(big.Int).Mod(x, v)
+	}
+	top := len(tree) - 1
+	r := []*big.Int{mod(n, tree[top][0])}
+	for level := top - 1; level >= 0; level-- {
+		next := make([]*big.Int, len(tree[level]))
+		for i, v := range tree[level] {
+			next[i] = mod(r[i/2], v)
+		}
+		r = next
+	}
+	return r
+}
+
+// BatchGCD returns, for each modulus N_i, the GCD of N_i with the product of all the
+// others, found in quasi-linear time as gcd(N_i, (P mod N_i²)/N_i) where P is the
+// product of all. A result other than 1 reveals a factor N_i shares with another, as
+// happens with RSA keys made from poor randomness; a result equal to N_i means that each
+// of its prime factors is shared, or that N_i is repeated. The moduli must be positive;
+// BatchGCD returns nil otherwise.
+func BatchGCD(moduli []*big.Int) []*big.Int {
+	if len(moduli) == 0 {
+		return nil
+	}
+	for _, n := range moduli {
+		if n.Sign() <= 0 {
+			return nil
+		}
+	}
+	tree := productTree(moduli)
+	r := remainders(tree[len(tree)-1][0], tree, true)
+	for i, n := range moduli {
+		r[i].Quo(r[i], n)
+		r[i].GCD(nil, nil, r[i], n)
+	}
+	return r
+}
+
+// BatchSmooth reports, for each value, whether all its prime factors are among the
+// primes of the sieve, by Bernstein's batch smoothness test: with P the product of those
+// primes, P mod x is found for every x at once by a remainder tree, and x is smooth
+// exactly when it divides (P mod x)^k for some k as large as log₂ x, the most times any
+// prime can divide it. The k used is a power of two, reached by squaring mod x. The
+// number 1 is smooth and 0 is not; signs are ignored.
+func BatchSmooth(values []*big.Int, sieve *Sieve) []bool {
+	smooth := make([]bool, len(values))
+	var xs []*big.Int
+	var index []int
+	for i, v := range values {
+		if v.Sign() != 0 {
+			xs = append(xs, new(big.Int).Abs(v))
+			index = append(index, i)
+		}
+	}
+	if len(xs) == 0 {
+		return smooth
+	}
+
+	var primes []*big.Int
+	for p := range sieve.primes(2, sieve.size) {
+		primes = append(primes, big.NewInt(int64(p)))
+	}
+	P := big.NewInt(1)
+	if len(primes) > 0 {
+		ptree := productTree(primes)
+		P = ptree[len(ptree)-1][0]
+	}
+
+	r := remainders(P, productTree(xs), false)
+	for i, x := range xs {
+		y := r[i]
+		for k := 1; k < x.BitLen(); k <<= 1 {
+			y.Mul(y, y).Mod(y, x)
+		}
+		smooth[index[i]] = y.Sign() == 0
+	}
+	return smooth
+}
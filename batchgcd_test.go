@@ -0,0 +1,140 @@
+package sieve
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// weakModuli returns count RSA-style moduli of twice the given bits, made from a pool of
+// primes small enough that some are shared.
+func weakModuli(sieve *Sieve, count, pool, bits int) []*big.Int {
+	r := NewSeededReader([]byte("batch"))
+	primes := make([]*big.Int, pool)
+	for i := range primes {
+		primes[i], _ = sieve.RandomPrime(bits, r)
+	}
+	pick := rand.New(rand.NewSource(1))
+	moduli := make([]*big.Int, count)
+	for i := range moduli {
+		p, q := pick.Intn(pool), pick.Intn(pool)
+		for q == p {
+			q = pick.Intn(pool)
+		}
+		moduli[i] = new(big.Int).Mul(primes[p], primes[q])
+	}
+	return moduli
+}
+
+func TestBatchGCD(t *testing.T) {
+	sieve := New(1 << 16)
+	for _, count := range []int{1, 2, 3, 7, 64, 301} {
+		moduli := weakModuli(sieve, count, 3*count, 64)
+		have := BatchGCD(moduli)
+		for i, n := range moduli {
+			want := big.NewInt(1)
+			for j, m := range moduli {
+				if j != i {
+					want.Mul(want, m)
+				}
+			}
+			want.GCD(nil, nil, want, n)
+			if have[i].Cmp(want) != 0 {
+				t.Errorf("count %d, BatchGCD #%d = %v; want %v", count, i, have[i], want)
+			}
+		}
+	}
+
+	moduli := []*big.Int{big.NewInt(15), big.NewInt(35), big.NewInt(77), big.NewInt(13), big.NewInt(15)}
+	if have, want := fmt.Sprint(BatchGCD(moduli)), "[15 35 7 1 15]"; have != want {
+		t.Errorf("BatchGCD(15 35 77 13 15) = %s; want %s", have, want)
+	}
+	if g := BatchGCD(nil); g != nil {
+		t.Errorf("BatchGCD(nil) = %v", g)
+	}
+	if g := BatchGCD([]*big.Int{big.NewInt(6), big.NewInt(0)}); g != nil {
+		t.Errorf("BatchGCD(6 0) = %v", g)
+	}
+}
+
+// smoothByTrial is the reference for BatchSmooth: divide out every prime of the sieve.
+func smoothByTrial(v *big.Int, sieve *Sieve) bool {
+	if v.Sign() == 0 {
+		return false
+	}
+	x := new(big.Int).Abs(v)
+	var q, r big.Int
+	for p := range sieve.primes(2, sieve.size) {
+		bp := big.NewInt(int64(p))
+		for q.QuoRem(x, bp, &r); r.Sign() == 0; q.QuoRem(x, bp, &r) {
+			x.Set(&q)
+		}
+	}
+	return x.Cmp(bigOne) == 0
+}
+
+func TestBatchSmooth(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for _, size := range []int{1, 2, 100, 10000} {
+		sieve := New(size)
+		var values []*big.Int
+		for range 300 {
+			v := big.NewInt(1)
+			for range r.Intn(20) {
+				v.Mul(v, big.NewInt(int64(r.Intn(size+50)+1)))
+			}
+			if r.Intn(10) == 0 {
+				v.Neg(v)
+			}
+			values = append(values, v)
+		}
+		values = append(values, big.NewInt(0), big.NewInt(1), new(big.Int).Lsh(bigOne, 200))
+		for i, s := range BatchSmooth(values, sieve) {
+			if want := smoothByTrial(values[i], sieve); s != want {
+				t.Errorf("size %d, BatchSmooth(%v) = %v; want %v", size, values[i], s, want)
+			}
+		}
+	}
+	if s := BatchSmooth(nil, New(100)); len(s) != 0 {
+		t.Errorf("BatchSmooth(nil) = %v", s)
+	}
+}
+
+func BenchmarkBatchGCD(b *testing.B) {
+	moduli := weakModuli(New(1<<16), 1000, 3000, 256)
+	for b.Loop() {
+		BatchGCD(moduli)
+	}
+}
+
+func benchmarkSmooth(b *testing.B, batch bool) {
+	sieve := New(1 << 16)
+	r := rand.New(rand.NewSource(3))
+	values := make([]*big.Int, 1000)
+	for i := range values {
+		values[i] = new(big.Int).Rand(r, new(big.Int).Lsh(bigOne, 256))
+	}
+	for b.Loop() {
+		if batch {
+			BatchSmooth(values, sieve)
+		} else {
+			for _, v := range values {
+				smoothByTrial(v, sieve)
+			}
+		}
+	}
+}
+
+func BenchmarkBatchSmooth(b *testing.B) { benchmarkSmooth(b, true) }
+func BenchmarkTrialSmooth(b *testing.B) { benchmarkSmooth(b, false) }
+
+func ExampleBatchGCD() {
+	moduli := []*big.Int{
+		big.NewInt(3 * 5), // shares 5 with 5·7
+		big.NewInt(5 * 7),
+		big.NewInt(11 * 13),
+	}
+	fmt.Println(BatchGCD(moduli))
+	// Output: [5 5 1]
+}
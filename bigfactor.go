@@ -0,0 +1,293 @@
+package sieve
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// BigUnique is a prime factor of a big.Int with its multiplicity, as Unique is for int.
+// Prime is false for a composite factor that could not be split.
+type BigUnique struct {
+	Factor *big.Int
+	Count  int
+	Prime  bool
+}
+
+// PrimeBig tests primality of any non-negative big.Int: exactly when it fits in 64 bits
+// (by the sieve, trial division, or the Baillie-PSW test, as for Prime) and beyond that
+// by trial division with the smaller primes of the sieve followed by the Baillie-PSW
+// test, to which no counterexample is known.
+func (sieve *Sieve) PrimeBig(n *big.Int) bool {
+	if n.IsInt64() {
+		return sieve.Prime(int(n.Int64()))
+	}
+	return sieve.probablyPrime(n)
+}
+
+// trialBits is the size that products of the sieve's primes reach before each is
+// reduced with a GCD against the number being factored.
+const trialBits = 1 << 12
+
+// Limits on the work spent splitting a composite cofactor.
+const (
+	pm1Bound      = 100000  // Pollard's p-1 stage one bound
+	rhoIterations = 1 << 22 // Pollard-Brent rho iterations per polynomial
+	rhoAttempts   = 4       // polynomials x²+c tried
+)
+
+// FactorBig factors a big.Int. Values within the sieve's reach are factored as by
+// FactorUnique. Larger ones are trial divided by every prime in the sieve, taken in
+// products of a few thousand bits whose GCD with n reveals at once whether any of them
+// divides it. What remains is tested with PrimeBig and, if composite, split by Pollard's
+// p-1 method and the Pollard-Brent rho method. A composite that resists both within
+// their work limits is reported with Prime false. Factors are in ascending order.
+func (sieve *Sieve) FactorBig(n *big.Int) []BigUnique {
+	if n.Sign() < 0 {
+		return nil
+	}
+	if n.IsInt64() && sieve.reaches(int(n.Int64())) {
+		var result []BigUnique
+		for _, f := range sieve.FactorUnique(int(n.Int64())) {
+			result = append(result, BigUnique{big.NewInt(int64(f.Factor)), f.Count, sieve.Prime(f.Factor)})
+		}
+		return result
+	}
+
+	factors, m := sieve.trialFactor(n)
+	found, unsplit := sieve.split(m, rhoIterations)
+	factors = append(factors, found...)
+	factors = append(factors, unsplit...)
+	composite := make(map[string]bool)
+	for _, c := range unsplit {
+		composite[c.String()] = true
+	}
+
+	slices.SortFunc(factors, func(a, b *big.Int) int { return a.Cmp(b) })
+	var result []BigUnique
+	for _, f := range factors {
+		if k := len(result) - 1; k >= 0 && result[k].Factor.Cmp(f) == 0 {
+			result[k].Count++
+			continue
+		}
+		result = append(result, BigUnique{f, 1, !composite[f.String()]})
+	}
+	if len(result) == 0 { // n is 0 or 1
+		result = append(result, BigUnique{new(big.Int).Set(n), 1, false})
+	}
+	return result
+}
+
+// FactorStringBig formats the factorization of n as FactorString does, "2^3 3 5", with
+// any composite that could not be split enclosed in parentheses.
+func (sieve *Sieve) FactorStringBig(n *big.Int) string {
+	r := ""
+	space := ""
+	for _, f := range sieve.FactorBig(n) {
+		s := f.Factor.String()
+		if !f.Prime && f.Factor.Cmp(bigOne) > 0 {
+			s = "(" + s + ")"
+		}
+		if f.Count == 1 {
+			r = r + fmt.Sprintf("%s%s", space, s)
+		} else {
+			r = r + fmt.Sprintf("%s%s^%d", space, s, f.Count)
+		}
+		space = " "
+	}
+	return r
+}
+
+var bigOne = big.NewInt(1)
+
+// trialFactor divides n > 0 by every prime of the sieve, returning the prime factors
+// found, with repetition and in ascending order, and what remains. Powers of two are
+// removed by shifting, and the odd primes are taken in products of a few thousand bits
+// whose GCD with n reveals at once whether any of them divides it. The search stops
+// early once what remains is 1 or must be prime.
+func (sieve *Sieve) trialFactor(n *big.Int) (factors []*big.Int, rest *big.Int) {
+	m := new(big.Int).Set(n)
+	if z := m.TrailingZeroBits(); z > 0 {
+		for range z {
+			factors = append(factors, big.NewInt(2))
+		}
+		m.Rsh(m, z)
+	}
+
+	var g, q, r big.Int
+	product := big.NewInt(1)
+	var primes []int
+	flush := func() {
+		if g.GCD(nil, nil, m, product).Cmp(bigOne) != 0 {
+			for _, p := range primes {
+				bp := big.NewInt(int64(p))
+				for q.QuoRem(m, bp, &r); r.Sign() == 0; q.QuoRem(m, bp, &r) {
+					factors = append(factors, bp)
+					m.Set(&q)
+				}
+			}
+		}
+		product.SetInt64(1)
+		primes = primes[:0]
+	}
+	for p := 3; p <= sieve.size; p += 2 {
+		if sieve.bit(p) != 0 {
+			continue
+		}
+		if m.Cmp(big.NewInt(int64(p)*int64(p))) < 0 {
+			break // what remains is 1 or prime
+		}
+		product.Mul(product, big.NewInt(int64(p)))
+		primes = append(primes, p)
+		if product.BitLen() >= trialBits {
+			flush()
+		}
+	}
+	flush()
+	return factors, m
+}
+
+// split factors c, which has no small factors, into the primes it can find, in no
+// particular order, and the composites that resist Pollard's p-1 method and the rho
+// method with the given number of iterations per polynomial. Composites of 64 bits are
+// first given to the rho method in Montgomery arithmetic, much the fastest way to split
+// them.
+func (sieve *Sieve) split(c *big.Int, iterations int) (factors, unsplit []*big.Int) {
+	pending := []*big.Int{c}
+	for len(pending) > 0 {
+		c := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		switch {
+		case c.Cmp(bigOne) == 0:
+		case sieve.PrimeBig(c):
+			factors = append(factors, c)
+		default:
+			var d *big.Int
+			if c.IsUint64() {
+				if f := pollardRho64(c.Uint64(), iterations); f != 0 {
+					d = new(big.Int).SetUint64(f)
+				}
+			}
+			if d == nil {
+				d = sieve.pollardPM1(c)
+			}
+			if d == nil {
+				d = pollardRho(c, iterations)
+			}
+			if d == nil {
+				unsplit = append(unsplit, c)
+				continue
+			}
+			pending = append(pending, d, new(big.Int).Quo(c, d))
+		}
+	}
+	return factors, unsplit
+}
+
+// pollardPM1 seeks a factor p of the odd composite n for which p-1 is pm1Bound-smooth
+// (Pollard, 1974), computing a = 2^E mod n, where E is the product of the prime powers
+// up to the bound, and taking gcd(a-1, n). It returns nil if that finds no proper factor.
+func (sieve *Sieve) pollardPM1(n *big.Int) *big.Int {
+	a := big.NewInt(2)
+	var e, g big.Int
+	for p := range sieve.primes(2, pm1Bound) {
+		q := p
+		for q <= pm1Bound/p {
+			q *= p
+		}
+		a.Exp(a, e.SetInt64(int64(q)), n)
+	}
+	g.GCD(nil, nil, e.Sub(a, bigOne), n)
+	if g.Cmp(bigOne) == 0 || g.Cmp(n) == 0 {
+		return nil
+	}
+	return &g
+}
+
+// pollardRho seeks a factor of the odd composite n by Brent's variant (1980) of
+// Pollard's rho method, iterating x → x²+c and accumulating the product of the
+// differences |x-y| so that one GCD serves a hundred steps. It returns nil if no proper
+// factor appears within about the given number of iterations for each of a few
+// polynomials.
+func pollardRho(n *big.Int, iterations int) *big.Int {
+	const batch = 128
+	var x, y, ys, q, t, g big.Int
+	for c := int64(1); c <= rhoAttempts; c++ {
+		C := big.NewInt(c)
+		step := func(v *big.Int) { v.Mul(v, v).Add(v, C).Mod(v, n) }
+		y.SetInt64(2)
+		q.SetInt64(1)
+		g.SetInt64(1)
+		for r := 1; g.Cmp(bigOne) == 0 && r <= iterations; r <<= 1 {
+			x.Set(&y)
+			for range r {
+				step(&y)
+			}
+			for k := 0; k < r && g.Cmp(bigOne) == 0; k += batch {
+				ys.Set(&y)
+				for range min(batch, r-k) {
+					step(&y)
+					q.Mul(&q, t.Sub(&x, &y).Abs(&t)).Mod(&q, n)
+				}
+				g.GCD(nil, nil, &q, n)
+			}
+		}
+		if g.Cmp(n) == 0 { // the batch overshot; retrace it one step at a time
+			for {
+				step(&ys)
+				if g.GCD(nil, nil, t.Sub(&x, &ys).Abs(&t), n).Cmp(bigOne) != 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(bigOne) != 0 && g.Cmp(n) != 0 {
+			return new(big.Int).Set(&g)
+		}
+	}
+	return nil
+}
+
+// pollardRho64 is pollardRho for an odd composite n of 64 bits, iterating in Montgomery
+// form, where x-y still has the GCD with n that matters. It returns 0 if no proper factor
+// appears.
+func pollardRho64(n uint64, iterations int) uint64 {
+	const batch = 128
+	mt := modarith.NewMontgomery(n)
+	if mt == nil {
+		return 0
+	}
+	for c := uint64(1); c <= rhoAttempts; c++ {
+		C := mt.To(c)
+		step := func(v uint64) uint64 { return mt.Add(mt.Mul(v, v), C) }
+		var x, ys uint64
+		y, q, g := mt.To(2), mt.One(), uint64(1)
+		for r := 1; g == 1 && r <= iterations; r <<= 1 {
+			x = y
+			for range r {
+				y = step(y)
+			}
+			for k := 0; k < r && g == 1; k += batch {
+				ys = y
+				for range min(batch, r-k) {
+					y = step(y)
+					q = mt.Mul(q, mt.Sub(x, y))
+				}
+				g = modarith.GCD(q, n)
+			}
+		}
+		if g == n { // the batch overshot; retrace it one step at a time
+			for {
+				ys = step(ys)
+				if g = modarith.GCD(mt.Sub(x, ys), n); g != 1 {
+					break
+				}
+			}
+		}
+		if g != 1 && g != n {
+			return g
+		}
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package sieve
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestFactorBigSmall(t *testing.T) {
+	sieve := New(100)
+	for n := 0; n <= 10000; n++ {
+		have := sieve.FactorStringBig(big.NewInt(int64(n)))
+		if want := sieve.FactorString(n); have != want {
+			t.Errorf("FactorStringBig(%d) = %q; want %q", n, have, want)
+		}
+	}
+}
+
+var factorBigTests = []struct {
+	n       string
+	factors string
+}{
+	{"18446744073709551617", "274177 67280421310721"},                            // 2^64+1
+	{"147573952589676412927", "193707721 761838257287"},                          // 2^67-1
+	{"18446743979220271189", "4294967279 4294967291"},                            // beyond int64
+	{"1000000000000000000000000000000", "2^30 5^30"},                             // 10^30
+	{"1298074214633706835075030044377087", "1298074214633706835075030044377087"}, // a prime
+	{"604462909807314587353088", "2^79"},                                         // a power of two
+	{"1594476335558328832077274700191465833717709", "7 23 1000003^2 4294967291 2305843009213693951"},
+}
+
+func TestFactorBig(t *testing.T) {
+	for _, size := range []int{100, 1000000} {
+		sieve := New(size)
+		for i, a := range factorBigTests {
+			n, _ := new(big.Int).SetString(a.n, 10)
+			if s := sieve.FactorStringBig(n); s != a.factors {
+				t.Errorf("#%d, size %d, FactorStringBig(%s) = %q; want %q", i, size, a.n, s, a.factors)
+			}
+			product := big.NewInt(1)
+			for _, f := range sieve.FactorBig(n) {
+				if !f.Prime {
+					t.Errorf("#%d, size %d, factor %v of %s not prime", i, size, f.Factor, a.n)
+				}
+				product.Mul(product, new(big.Int).Exp(f.Factor, big.NewInt(int64(f.Count)), nil))
+			}
+			if product.Cmp(n) != 0 {
+				t.Errorf("#%d, size %d, factors of %s multiply to %v", i, size, a.n, product)
+			}
+		}
+	}
+}
+
+func TestPollardRho64(t *testing.T) {
+	for _, n := range []uint64{
+		15, 1000003 * 1000033, 4294967279 * 4294967291, 3 * 6148914691236517203, 1<<64 - 1, 2147483647 * 2147483647,
+	} {
+		d := pollardRho64(n, rhoIterations)
+		if d <= 1 || d >= n || n%d != 0 {
+			t.Errorf("pollardRho64(%d) = %d", n, d)
+		}
+	}
+	if d := pollardRho64(1<<40, rhoIterations); d != 0 {
+		t.Errorf("pollardRho64(2^40) = %d", d)
+	}
+}
+
+func TestPrimeBig(t *testing.T) {
+	sieve := New(1000)
+	for i, a := range []struct {
+		n     string
+		prime bool
+	}{
+		{"0", false},
+		{"-7", false},
+		{"997", true},
+		{"1000003", true},
+		{"18446744073709551557", true}, // largest prime below 2^64
+		{"18446744073709551617", false},
+		{"170141183460469231731687303715884105727", true}, // 2^127-1
+		{"3317044064679887385961981", false},              // strong pseudoprime to bases 2 through 37
+	} {
+		n, _ := new(big.Int).SetString(a.n, 10)
+		if p := sieve.PrimeBig(n); p != a.prime {
+			t.Errorf("#%d, PrimeBig(%s) = %v; want %v", i, a.n, p, a.prime)
+		}
+	}
+}
+
+func BenchmarkFactorBig(b *testing.B) {
+	sieve := New(1000000)
+	n, _ := new(big.Int).SetString("18446743979220271189", 10)
+	for b.Loop() {
+		sieve.FactorBig(n)
+	}
+}
+
+func ExampleSieve_FactorStringBig() {
+	n := new(big.Int).Lsh(big.NewInt(1), 64)
+	n.Add(n, big.NewInt(1)) // Fermat number F6
+	fmt.Println(New(1000).FactorStringBig(n))
+	// Output:
+	// 274177 67280421310721
+}
+
+func TestReaches(t *testing.T) {
+	for i, a := range []struct {
+		size, n int
+		reaches bool
+	}{
+		{10, 100, true},
+		{10, 101, false},
+		{0, 1, false},
+		{1 << 31, 1 << 62, true},
+		{1 << 32, 1<<63 - 1, true}, // Size()*Size() overflows
+		{3037000499, 1<<63 - 1, false},
+		{3037000500, 1<<63 - 1, true},
+	} {
+		if r := (&Sieve{size: a.size}).reaches(a.n); r != a.reaches {
+			t.Errorf("#%d, size %d reaches %d = %v; want %v", i, a.size, a.n, r, a.reaches)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package sieve
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+)
+
+// Certificate is a proof that N is prime which Verify can check without the sieve. Small
+// primes are proved by trial division. Larger ones rest on the prime factors of N-1.
+// Each factor carries a witness and its own certificate, so the proof is recursive.
+//
+//	trial        N < 2^20. Verify trial divides it.
+//	pratt        N-1 is fully factored (Lucas 1876, Pratt 1975).
+//	pocklington  The factored part F of N-1 exceeds √N (Pocklington 1914).
+//	bls          F exceeds ∛N, with the test of Brillhart, Lehmer and Selfridge (1975).
+//
+// Numbers are decimal strings so that the JSON form holds integers of any size.
+type Certificate struct {
+	N       string       `json:"n"`
+	Method  string       `json:"method"`
+	Factors []CertFactor `json:"factors,omitempty"`
+}
+
+// CertFactor is a prime factor P^Count of N-1 with a witness a. For pratt, a^(N-1) ≡ 1
+// and a^((N-1)/P) ≢ 1 (mod N). For pocklington and bls, gcd(a^((N-1)/P)-1, N) must also
+// be 1. Cert proves that P is prime.
+type CertFactor struct {
+	P       string       `json:"p"`
+	Count   int          `json:"count"`
+	Witness string       `json:"witness"`
+	Cert    *Certificate `json:"cert"`
+}
+
+// certTrial is the bound below which primes are certified by trial division.
+const certTrial = 1 << 20
+
+// Limits on the work spent finding a certificate.
+const (
+	certRho       = 1 << 16 // rho iterations per polynomial when factoring N-1
+	certWitnesses = 1 << 10 // witnesses tried for each factor
+)
+
+// Certify proves that n is prime with a Pratt certificate. It factors n-1 with
+// FactorUnique when the sieve reaches that far and with FactorBig beyond. Each prime
+// factor q gets the smallest witness a with a^(n-1) ≡ 1 and a^((n-1)/q) ≢ 1 (mod n),
+// and q is certified in turn. It returns an error if n is not prime.
+func (sieve *Sieve) Certify(n uint64) (*Certificate, error) {
+	N := new(big.Int).SetUint64(n)
+	if !isPrime64(n) {
+		return nil, fmt.Errorf("sieve: %v is not prime", N)
+	}
+	if n < certTrial {
+		return &Certificate{N: N.String(), Method: "trial"}, nil
+	}
+
+	var factors []BigUnique
+	if m := n - 1; m <= math.MaxInt && sieve.reaches(int(m)) {
+		for _, f := range sieve.FactorUnique(int(m)) {
+			factors = append(factors, BigUnique{big.NewInt(int64(f.Factor)), f.Count, true})
+		}
+	} else {
+		factors = sieve.FactorBig(new(big.Int).SetUint64(m))
+	}
+	var list []*big.Int
+	for _, f := range factors {
+		if !f.Prime { // not expected below 2^64
+			return nil, fmt.Errorf("sieve: cannot factor %v-1", N)
+		}
+		for range f.Count {
+			list = append(list, f.Factor)
+		}
+	}
+	return sieve.certify(N, list)
+}
+
+// CertifyBig proves that n is prime. Values that fit in 64 bits are certified as by
+// Certify. Larger n-1 are partially factored: by trial division with the sieve, and
+// then by Pollard's p-1 and rho methods with modest limits. The factored part F gives a
+// Pratt certificate if it is all of n-1. It gives a Pocklington certificate if F² > n,
+// or a Brillhart-Lehmer-Selfridge certificate if F³ > n. Otherwise CertifyBig gives up
+// with an error. It also returns an error if n is not prime.
+func (sieve *Sieve) CertifyBig(n *big.Int) (*Certificate, error) {
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("sieve: %v is not prime", n)
+	}
+	if n.IsUint64() {
+		return sieve.Certify(n.Uint64())
+	}
+	if !sieve.PrimeBig(n) {
+		return nil, fmt.Errorf("sieve: %v is not prime", n)
+	}
+	m := new(big.Int).Sub(n, bigOne)
+	list, rest := sieve.trialFactor(m)
+	found, _ := sieve.split(rest, certRho) // unsplit composites stay in the unfactored part
+	return sieve.certify(n, append(list, found...))
+}
+
+// certify builds the certificate for the prime N given prime factors of N-1, with
+// repetition, and certifies each factor.
+func (sieve *Sieve) certify(N *big.Int, list []*big.Int) (*Certificate, error) {
+	m := new(big.Int).Sub(N, bigOne)
+	F := big.NewInt(1)
+	counts := make(map[string]int)
+	var primes []*big.Int
+	for _, q := range list {
+		if counts[q.String()] == 0 {
+			primes = append(primes, q)
+		}
+		counts[q.String()]++
+		F.Mul(F, q)
+	}
+	slices.SortFunc(primes, func(a, b *big.Int) int { return a.Cmp(b) })
+
+	cert := &Certificate{N: N.String()}
+	switch {
+	case F.Cmp(m) == 0:
+		cert.Method = "pratt"
+	case new(big.Int).Mul(F, F).Cmp(N) > 0:
+		cert.Method = "pocklington"
+	case new(big.Int).Exp(F, big.NewInt(3), nil).Cmp(N) > 0:
+		if blsSquare(N, F) {
+			return nil, fmt.Errorf("sieve: %v is not prime", N) // by the theorem
+		}
+		cert.Method = "bls"
+	default:
+		return nil, fmt.Errorf("sieve: too little of %v-1 is factored: %v", N, F)
+	}
+
+	for _, q := range primes {
+		a, err := witness(N, q, cert.Method != "pratt")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := sieve.CertifyBig(q)
+		if err != nil {
+			return nil, err
+		}
+		cert.Factors = append(cert.Factors, CertFactor{q.String(), counts[q.String()], a.String(), sub})
+	}
+	return cert, nil
+}
+
+// witness returns the smallest a >= 2 meeting the conditions of CertFactor for q, the
+// GCD condition included when coprime is set.
+func witness(N, q *big.Int, coprime bool) (*big.Int, error) {
+	m := new(big.Int).Sub(N, bigOne)
+	e := new(big.Int).Quo(m, q)
+	var x, g big.Int
+	for a := int64(2); a < certWitnesses+2; a++ {
+		A := big.NewInt(a)
+		if A.Cmp(N) >= 0 {
+			break
+		}
+		if x.Exp(A, m, N).Cmp(bigOne) != 0 {
+			return nil, fmt.Errorf("sieve: %v is not prime: %d^(n-1) ≢ 1", N, a)
+		}
+		x.Exp(A, e, N)
+		if coprime && g.GCD(nil, nil, x.Sub(&x, bigOne), N).Cmp(bigOne) == 0 ||
+			!coprime && x.Cmp(bigOne) != 0 {
+			return A, nil
+		}
+	}
+	return nil, fmt.Errorf("sieve: no witness for the factor %v of %v-1", q, N)
+}
+
+// blsSquare writes N = c₂F² + c₁F + 1 in base F and reports whether c₁²-4c₂ is a
+// perfect square. When F³ > N and every prime factor of N is 1 mod F, N is prime
+// exactly when it is not.
+func blsSquare(N, F *big.Int) bool {
+	var c1, c2 big.Int
+	c2.QuoRem(new(big.Int).Quo(new(big.Int).Sub(N, bigOne), F), F, &c1)
+	d := new(big.Int).Mul(&c1, &c1)
+	d.Sub(d, c2.Lsh(&c2, 2))
+	if d.Sign() < 0 {
+		return false
+	}
+	r := new(big.Int).Sqrt(d)
+	return r.Mul(r, r).Cmp(d) == 0
+}
+
+// Verify checks a certificate. It uses only math/big and no sieve or probabilistic test,
+// so it is independent of the code that made the certificate. It returns nil when the
+// certificate proves that N is prime, and otherwise an error naming the first flaw.
+func Verify(cert *Certificate) error {
+	if cert == nil {
+		return errors.New("sieve: missing certificate")
+	}
+	N, ok := new(big.Int).SetString(cert.N, 10)
+	if !ok {
+		return fmt.Errorf("sieve: certificate for %q: not an integer", cert.N)
+	}
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("sieve: certificate for %v: %s", N, fmt.Sprintf(format, args...))
+	}
+	if N.Cmp(big.NewInt(2)) < 0 {
+		return fail("less than 2")
+	}
+
+	if cert.Method == "trial" {
+		if N.Cmp(big.NewInt(certTrial)) >= 0 {
+			return fail("too large for trial division")
+		}
+		n := N.Int64()
+		for d := int64(2); d*d <= n; d++ {
+			if n%d == 0 {
+				return fail("divisible by %d", d)
+			}
+		}
+		return nil
+	}
+
+	m := new(big.Int).Sub(N, bigOne)
+	F := big.NewInt(1)
+	var prev, x, e, g big.Int
+	for i, f := range cert.Factors {
+		q, ok := new(big.Int).SetString(f.P, 10)
+		if !ok || q.Cmp(bigOne) <= 0 {
+			return fail("factor %q is not an integer > 1", f.P)
+		}
+		if i > 0 && q.Cmp(&prev) <= 0 {
+			return fail("factors are not in ascending order")
+		}
+		prev.Set(q)
+		if f.Count < 1 {
+			return fail("factor %v has count %d", q, f.Count)
+		}
+		for range f.Count {
+			if F.Mul(F, q).Cmp(m) > 0 {
+				break // caught below, before a huge count can run long
+			}
+		}
+		if new(big.Int).Rem(m, F).Sign() != 0 {
+			return fail("factors do not divide N-1")
+		}
+		if f.Cert == nil || f.Cert.N != q.String() {
+			return fail("factor %v lacks its certificate", q)
+		}
+		if err := Verify(f.Cert); err != nil {
+			return err
+		}
+
+		a, ok := new(big.Int).SetString(f.Witness, 10)
+		if !ok || a.Cmp(bigOne) <= 0 || a.Cmp(N) >= 0 {
+			return fail("witness %q for %v is not in (1, N)", f.Witness, q)
+		}
+		if x.Exp(a, m, N).Cmp(bigOne) != 0 {
+			return fail("%v^(N-1) ≢ 1", a)
+		}
+		x.Exp(a, e.Quo(m, q), N)
+		switch cert.Method {
+		case "pratt":
+			if x.Cmp(bigOne) == 0 {
+				return fail("%v^((N-1)/%v) ≡ 1", a, q)
+			}
+		default:
+			if g.GCD(nil, nil, x.Sub(&x, bigOne), N).Cmp(bigOne) != 0 {
+				return fail("gcd(%v^((N-1)/%v)-1, N) ≠ 1", a, q)
+			}
+		}
+	}
+
+	switch cert.Method {
+	case "pratt":
+		if F.Cmp(m) != 0 {
+			return fail("factors multiply to %v, not N-1", F)
+		}
+	case "pocklington":
+		if new(big.Int).Mul(F, F).Cmp(N) <= 0 {
+			return fail("factored part %v does not exceed √N", F)
+		}
+	case "bls":
+		if new(big.Int).Exp(F, big.NewInt(3), nil).Cmp(N) <= 0 {
+			return fail("factored part %v does not exceed ∛N", F)
+		}
+		if blsSquare(N, F) {
+			return fail("c₁²-4c₂ is a square")
+		}
+	default:
+		return fail("unknown method %q", cert.Method)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package sieve
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestCertify(t *testing.T) {
+	sieve := New(1 << 16)
+	for i, n := range []uint64{
+		2, 3, 5, 1000003, 1<<20 + 7, 2147483647, 4294967291, 1000000000000000003,
+		2305843009213693951, 18446744073709551557,
+	} {
+		cert, err := sieve.Certify(n)
+		if err != nil {
+			t.Errorf("#%d, Certify(%d) failed: %v", i, n, err)
+			continue
+		}
+		if cert.N != fmt.Sprint(n) {
+			t.Errorf("#%d, Certify(%d) certifies %s", i, n, cert.N)
+		}
+		if err := Verify(cert); err != nil {
+			t.Errorf("#%d, Verify(Certify(%d)) = %v", i, n, err)
+		}
+	}
+
+	// every prime in a window, on both sides of the trial-division bound
+	for _, lo := range []uint64{certTrial - 1000, 1 << 40} {
+		for n := lo; n < lo+1000; n++ {
+			cert, err := sieve.Certify(n)
+			if isPrime64(n) != (err == nil) {
+				t.Errorf("Certify(%d) error %v", n, err)
+				continue
+			}
+			if err == nil && Verify(cert) != nil {
+				t.Errorf("Verify(Certify(%d)) = %v", n, Verify(cert))
+			}
+		}
+	}
+}
+
+var certifyBigTests = []struct {
+	n      string
+	method string
+}{
+	{"170141183460469231731687303715884105727", "pratt"}, // 2^127-1
+	{"618970019642690137449562111", "pratt"},             // 2^89-1
+	{"10601082969892295645966073094142206750254315805750263492345197008536238417", "pocklington"},
+	{"19395606219981742427523870049396027835745986656958169828647082255391709736958271759898313", "bls"},
+}
+
+func TestCertifyBig(t *testing.T) {
+	sieve := New(1 << 16)
+	for i, a := range certifyBigTests {
+		n, _ := new(big.Int).SetString(a.n, 10)
+		cert, err := sieve.CertifyBig(n)
+		if err != nil {
+			t.Errorf("#%d, CertifyBig(%s) failed: %v", i, a.n, err)
+			continue
+		}
+		if cert.Method != a.method {
+			t.Errorf("#%d, CertifyBig(%s) method %q; want %q", i, a.n, cert.Method, a.method)
+		}
+		if err := Verify(cert); err != nil {
+			t.Errorf("#%d, Verify(CertifyBig(%s)) = %v", i, a.n, err)
+		}
+	}
+}
+
+func TestCertifyComposite(t *testing.T) {
+	sieve := New(1000)
+	for i, n := range []uint64{0, 1, 4, 561, 1<<20 + 1, 3215031751, 18446744073709551615} {
+		if cert, err := sieve.Certify(n); err == nil {
+			t.Errorf("#%d, Certify(%d) = %+v", i, n, cert)
+		}
+	}
+	for i, s := range []string{"-7", "147573952589676412927", "340282366920938463463374607431768211457"} {
+		n, _ := new(big.Int).SetString(s, 10)
+		if cert, err := sieve.CertifyBig(n); err == nil {
+			t.Errorf("#%d, CertifyBig(%s) = %+v", i, s, cert)
+		}
+	}
+}
+
+func TestCertificateJSON(t *testing.T) {
+	sieve := New(1 << 16)
+	n, _ := new(big.Int).SetString(certifyBigTests[3].n, 10)
+	cert, err := sieve.CertifyBig(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Certificate
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(&back); err != nil {
+		t.Errorf("Verify after JSON round trip = %v", err)
+	}
+	if c, _ := json.Marshal(&back); string(c) != string(b) {
+		t.Errorf("JSON round trip changed the certificate:\n%s\n%s", b, c)
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	sieve := New(1 << 16)
+	tamper := []struct {
+		what   string
+		change func(c *Certificate)
+	}{
+		{"N", func(c *Certificate) { c.N = "2305843009213693953" }},
+		{"method", func(c *Certificate) { c.Method = "bls" }},
+		{"trial", func(c *Certificate) { c.Method, c.Factors = "trial", nil }},
+		{"unknown", func(c *Certificate) { c.Method = "faith" }},
+		{"witness 1", func(c *Certificate) { c.Factors[0].Witness = "1" }},
+		{"witness N", func(c *Certificate) { c.Factors[0].Witness = c.N }},
+		{"bad witness", func(c *Certificate) { c.Factors[1].Witness = "8" }}, // a cube, so 8^((N-1)/3) ≡ 1
+		{"factor", func(c *Certificate) { c.Factors[1].P = "9" }},
+		{"count", func(c *Certificate) { c.Factors[0].Count++ }},
+		{"huge count", func(c *Certificate) { c.Factors[0].Count = 1 << 40 }},
+		{"zero count", func(c *Certificate) { c.Factors[0].Count = 0 }},
+		{"missing factor", func(c *Certificate) { c.Factors = c.Factors[1:] }},
+		{"order", func(c *Certificate) { c.Factors[0], c.Factors[1] = c.Factors[1], c.Factors[0] }},
+		{"subcertificate", func(c *Certificate) { c.Factors[2].Cert = nil }},
+		{"subcertificate N", func(c *Certificate) { c.Factors[2].Cert.N = "7" }},
+		{"composite", func(c *Certificate) {
+			c.Factors[2].Cert = &Certificate{N: c.Factors[2].P, Method: "pratt"}
+		}},
+		{"not a number", func(c *Certificate) { c.N = "0x1f" }},
+	}
+	for i, a := range tamper {
+		cert, err := sieve.Certify(2305843009213693951) // 2^61-1
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.change(cert)
+		if err := Verify(cert); err == nil {
+			t.Errorf("#%d, Verify accepted a certificate with tampered %s", i, a.what)
+		}
+	}
+	if Verify(nil) == nil {
+		t.Errorf("Verify(nil) succeeded")
+	}
+	if Verify(&Certificate{N: "1000001", Method: "trial"}) == nil {
+		t.Errorf("Verify accepted trial division of 101·9901")
+	}
+	if Verify(&Certificate{N: "1", Method: "pratt"}) == nil {
+		t.Errorf("Verify accepted 1")
+	}
+}
+
+func BenchmarkCertify(b *testing.B) {
+	sieve := New(1 << 16)
+	for b.Loop() {
+		sieve.Certify(18446744073709551557)
+	}
+}
+
+func BenchmarkCertifyBig(b *testing.B) {
+	sieve := New(1 << 16)
+	n, _ := new(big.Int).SetString(certifyBigTests[0].n, 10)
+	for b.Loop() {
+		sieve.CertifyBig(n)
+	}
+}
+
+func ExampleSieve_Certify() {
+	sieve := New(1 << 16)
+	cert, _ := sieve.Certify(2147483647) // 2^31-1
+	fmt.Println(cert.Method, Verify(cert))
+	for _, f := range cert.Factors {
+		fmt.Printf("%s^%d witness %s (%s)\n", f.P, f.Count, f.Witness, f.Cert.Method)
+	}
+	// Output:
+	// pratt <nil>
+	// 2^1 witness 3 (trial)
+	// 3^2 witness 5 (trial)
+	// 7^1 witness 3 (trial)
+	// 11^1 witness 3 (trial)
+	// 31^1 witness 2 (trial)
+	// 151^1 witness 3 (trial)
+	// 331^1 witness 3 (trial)
+}
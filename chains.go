@@ -0,0 +1,188 @@
+package sieve
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// ChainKind selects the rule by which each member of a prime chain follows the last.
+type ChainKind int
+
+const (
+	FirstKind  ChainKind = iota // Cunningham chains p, 2p+1, 4p+3, ...
+	SecondKind                  // Cunningham chains p, 2p-1, 4p-3, ...
+	BiTwin                      // bi-twin chains n-1, n+1, 2n-1, 2n+1, 4n-1, 4n+1, ...
+)
+
+// Chain is a complete prime chain: one that cannot be extended at either end.
+type Chain struct {
+	Kind   ChainKind
+	Start  int // first prime, or for bi-twin chains the number n between the first pair
+	Length int // number of primes, or for bi-twin chains the number of twin pairs
+}
+
+// SophieGermain returns an iterator over the Sophie Germain primes in [lo, hi]: primes p
+// for which 2p+1 is also prime (http://oeis.org/A005384). The table is examined a word
+// at a time, each run of primes matched against the alternate bits of the run at 2p+1.
+func (sieve *Sieve) SophieGermain(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if lo <= 2 && 2 <= hi && !yield(2) { // 5 is prime
+			return
+		}
+		sieve.scanDoubled([]int{0}, []int{1}, lo, hi, func(exact bool, base, m uint64) bool {
+			for ; m != 0; m &= m - 1 {
+				p := base + 2*uint64(bits.TrailingZeros64(m))
+				if (exact || sieve.isPrime(p) && sieve.isPrime(2*p+1)) && !yield(int(p)) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// SafePrimes returns an iterator over the safe primes in [lo, hi]: primes p for which
+// (p-1)/2 is also prime (http://oeis.org/A005385). They are the images 2q+1 of the
+// Sophie Germain primes q.
+func (sieve *Sieve) SafePrimes(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for q := range sieve.SophieGermain(max(lo, 0)/2, (hi-1)/2) {
+			if !yield(2*q + 1) {
+				return
+			}
+		}
+	}
+}
+
+// CunninghamChains returns an iterator over the complete chains of the given kind having
+// at least k members (for bi-twin chains, k twin pairs) and starting in [lo, hi]. The
+// first two links of each chain are matched a word at a time, as in SophieGermain, and
+// the survivors followed individually, beyond the sieve if need be.
+func (sieve *Sieve) CunninghamChains(kind ChainKind, k, lo, hi int) iter.Seq[Chain] {
+	return func(yield func(Chain) bool) {
+		var near, far []int // offsets of n and of 2n matched a word at a time
+		switch kind {
+		case FirstKind:
+			near, far = []int{0}, []int{1}
+		case SecondKind:
+			near, far = []int{0}, []int{-1}
+		case BiTwin:
+			near, far = []int{0, 2}, []int{1, 3} // n-1 and n+1, then 2n-1 and 2n+1
+			lo, hi = lo-1, hi-1                  // scan the lesser twin
+		default:
+			return
+		}
+		if k < 2 {
+			far = nil
+		}
+		k = max(k, 1)
+
+		if kind != BiTwin && lo <= 2 && 2 <= hi { // the only even start
+			if c := sieve.chain(kind, 2); c.Length >= k && !yield(c) {
+				return
+			}
+		}
+		sieve.scanDoubled(near, far, lo, hi, func(exact bool, base, m uint64) bool {
+			for ; m != 0; m &= m - 1 {
+				n := base + 2*uint64(bits.TrailingZeros64(m))
+				if kind == BiTwin {
+					n++
+				}
+				if c := sieve.chain(kind, n); c.Length >= k && !yield(c) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// chain measures the complete chain starting at n, returning a zero Length if the chain
+// has a predecessor and so does not start at n.
+func (sieve *Sieve) chain(kind ChainKind, n uint64) Chain {
+	c := Chain{Kind: kind, Start: int(n)}
+	switch kind {
+	case FirstKind:
+		if n < 2 || n&1 == 1 && sieve.isPrime((n-1)/2) {
+			return c // n is not prime or extends a chain
+		}
+		for p := n; p <= maxPrime64/2 && sieve.isPrime(p); p = 2*p + 1 {
+			c.Length++
+		}
+	case SecondKind:
+		if n < 2 || n&1 == 1 && sieve.isPrime((n+1)/2) {
+			return c // n is not prime or extends a chain
+		}
+		for p := n; p <= maxPrime64/2 && sieve.isPrime(p); p = 2*p - 1 {
+			c.Length++
+		}
+	case BiTwin:
+		if n&1 == 0 && sieve.isPrime(n/2-1) && sieve.isPrime(n/2+1) {
+			return c // extends a chain
+		}
+		for m := n; m > 1 && m < maxPrime64/2 && sieve.isPrime(m-1) && sieve.isPrime(m+1); m *= 2 {
+			c.Length++
+		}
+	}
+	return c
+}
+
+// scanDoubled visits the odd n in [lo, hi] sixty-four at a time, each visit receiving a
+// mask whose bit j marks base+2*j as a candidate for which n+d is unstruck for each d in
+// near and 2n+c is unstruck for each c in far. Candidates are certain if exact and must be
+// confirmed otherwise. Visiting stops when visit returns false.
+func (sieve *Sieve) scanDoubled(near, far []int, lo, hi int, visit func(exact bool, base, m uint64) bool) {
+	if lo < 3 {
+		lo = 3
+	}
+	if hi < lo {
+		return
+	}
+	reach, low, high := 0, 0, 0 // extents of near and far
+	for _, d := range near {
+		reach = max(reach, d)
+	}
+	for _, c := range far {
+		low, high = min(low, c), max(high, c)
+	}
+	start, end := uint64(lo)|1, uint64(hi)
+
+	// run visits the starts in [start, last] using a for near and b for far
+	run := func(a, b *segment, last uint64) bool {
+		for base := start; base <= last; base += 128 {
+			m := ^uint64(0)
+			for _, d := range near {
+				m &= a.survivors(base + uint64(d))
+			}
+			for _, c := range far {
+				m &= b.doubled(base, c)
+			}
+			if count := (last-base)/2 + 1; count < 64 {
+				m &= 1<<count - 1
+			}
+			if m != 0 && !visit(a.exact && b.exact, base, m) {
+				return false
+			}
+		}
+		start = last + 2
+		return true
+	}
+
+	size := uint64(sieve.size)
+	if top := size - uint64(max(reach, high)); size > uint64(max(reach, high)) && start <= top/2 { // inside the sieve
+		last := min(end, top/2)
+		whole := sieve.whole()
+		if !run(whole, whole, last-(last-start)%2) {
+			return
+		}
+	}
+	for start <= end { // beyond the sieve
+		last := min(end, start+2*(scanWidth-1))
+		last -= (last - start) % 2
+		a := sieve.newSegment(start, last+uint64(reach), 0)
+		b := sieve.newSegment(2*start+uint64(low), 2*last+uint64(high), 0)
+		if !run(a, b, last) {
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package sieve
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSophieGermain(t *testing.T) {
+	for _, s := range []*Sieve{New(2000003), New(1000000), New(1000)} {
+		count := 0
+		for p := range s.SophieGermain(0, 1000000) {
+			if !s.isPrime(uint64(p)) || !s.isPrime(uint64(2*p+1)) {
+				t.Errorf("New(%d): %d is not a Sophie Germain prime", s.Size(), p)
+			}
+			count++
+		}
+		if count != 7746 {
+			t.Errorf("New(%d): %d Sophie Germain primes below 10^6; want %d", s.Size(), count, 7746)
+		}
+		safe := 0
+		for p := range s.SafePrimes(0, 1000000) {
+			if !s.isPrime(uint64(p)) || !s.isPrime(uint64(p-1)/2) {
+				t.Errorf("New(%d): %d is not a safe prime", s.Size(), p)
+			}
+			safe++
+		}
+		if safe != 4324 {
+			t.Errorf("New(%d): %d safe primes below 10^6; want %d", s.Size(), safe, 4324)
+		}
+	}
+
+	want := []int{2, 3, 5, 11, 23, 29, 41, 53, 83, 89, 113, 131} // http://oeis.org/A005384
+	if got := slices.Collect(New(300).SophieGermain(0, 131)); !slices.Equal(got, want) {
+		t.Errorf("SophieGermain(0, 131) = %v; want %v", got, want)
+	}
+	want = []int{5, 7, 11, 23, 47, 59, 83, 107, 167, 179, 227, 263} // http://oeis.org/A005385
+	if got := slices.Collect(New(300).SafePrimes(0, 263)); !slices.Equal(got, want) {
+		t.Errorf("SafePrimes(0, 263) = %v; want %v", got, want)
+	}
+}
+
+var chainTests = []struct {
+	kind   ChainKind
+	counts []int // complete chains starting <= 10^6 of length 1, 2, ...
+	first  []int // first start of a chain of length >= 1, 2, ...
+}{
+	{FirstKind, []int{67134, 5953, 949, 109, 24, 5}, []int{2, 2, 2, 2, 2, 89}},
+	{SecondKind, []int{67149, 5953, 967, 86, 37, 4, 2}, []int{2, 2, 2, 1531, 1531, 16651, 16651}},
+	{BiTwin, []int{7938, 135, 4, 1}, []int{4, 6, 211050, 253680}},
+}
+
+func TestCunninghamChains(t *testing.T) {
+	for _, s := range []*Sieve{New(2000003), New(1000)} {
+		for i, a := range chainTests {
+			counts := make([]int, len(a.counts))
+			for c := range s.CunninghamChains(a.kind, 1, 0, 1000000) {
+				if c.Length > len(counts) {
+					t.Errorf("#%d, New(%d): unexpected chain %+v", i, s.Size(), c)
+					continue
+				}
+				counts[c.Length-1]++
+			}
+			if !slices.Equal(counts, a.counts) {
+				t.Errorf("#%d, New(%d): chain counts %v; want %v", i, s.Size(), counts, a.counts)
+			}
+			for k, first := range a.first {
+				for c := range s.CunninghamChains(a.kind, k+1, 0, 1000000) {
+					if c.Start != first {
+						t.Errorf("#%d, New(%d): first chain of length %d is %+v; want start %d", i, s.Size(), k+1, c, first)
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+// Are long chains found far beyond the sieve?
+func TestCunninghamChainsBeyond(t *testing.T) {
+	s := New(100000)
+	// 1122659, 2245319, 4490639, 8981279, 17962559, 35925119, 71850239 (http://oeis.org/A005602)
+	for c := range s.CunninghamChains(FirstKind, 7, 1000000, 2000000) {
+		if c.Start != 1122659 || c.Length != 7 {
+			t.Errorf("first chain of length 7 is %+v; want start 1122659", c)
+		}
+		break
+	}
+}
+
+func ExampleSieve_CunninghamChains() {
+	// Find complete Cunningham chains of the first kind with at least five primes.
+	s := New(60000)
+	for c := range s.CunninghamChains(FirstKind, 5, 0, 60000) {
+		fmt.Println(c.Start, c.Length)
+	}
+	// Output:
+	// 2 5
+	// 89 6
+	// 53639 5
+	// 53849 5
+}
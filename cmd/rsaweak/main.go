@@ -0,0 +1,72 @@
+// Rsaweak checks RSA public keys for weaknesses that let them be factored, and writes a
+// JSON report to standard output.
+//
+// Usage:
+//
+//	rsaweak [-small n] [-fermat n] [-pm1 n] [file ...]
+//
+// Each file holds PEM public keys, RSA public keys and certificates, or one of them in DER
+// form; with no files, standard input is read. All the keys are checked as one set, so
+// that primes shared between them are found. The exit status is 1 if any key is weak and
+// 2 on error, including a file with no RSA public keys in it.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MichaelTJones/sieve/rsaweak"
+)
+
+func main() {
+	opts := rsaweak.DefaultOptions
+	flag.IntVar(&opts.SmallBound, "small", opts.SmallBound, "try prime factors up to `n`")
+	flag.IntVar(&opts.FermatIterations, "fermat", opts.FermatIterations, "take `n` steps of Fermat's method")
+	flag.IntVar(&opts.PM1Bound, "pm1", opts.PM1Bound, "use `n` as the bound of Pollard's p-1 method")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: rsaweak [flags] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var keys []*rsaweak.Key
+	read := func(name string, r io.Reader) {
+		data, err := io.ReadAll(r)
+		if err == nil {
+			var k []*rsaweak.Key
+			if k, err = rsaweak.ParseKeys(data); err == nil && len(k) == 0 {
+				err = errors.New("no RSA public keys")
+			}
+			if err == nil {
+				keys = append(keys, k...)
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "rsaweak: %s: %v\n", name, err)
+		os.Exit(2)
+	}
+	if flag.NArg() == 0 {
+		read("standard input", os.Stdin)
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rsaweak: %v\n", err)
+			os.Exit(2)
+		}
+		read(name, f)
+		f.Close()
+	}
+
+	report := rsaweak.New(opts).Check(keys)
+	if err := report.WriteJSON(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "rsaweak: %v\n", err)
+		os.Exit(2)
+	}
+	if report.Weak > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,169 @@
+package sieve
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// Admissible reports whether a prime constellation pattern can occur infinitely often
+// (per the Hardy-Littlewood k-tuple conjecture). A pattern lists offsets from its first
+// member, beginning with 0 and strictly increasing, as in {0, 2, 6} for n, n+2, n+6. It
+// is admissible when, for every prime p, the offsets miss at least one residue class
+// modulo p. Only p <= len(pattern) can cover every class, so only those are checked.
+func Admissible(pattern []int) bool {
+	return valid(pattern) && covering(pattern) == 0
+}
+
+// valid reports whether pattern begins with 0 and strictly increases.
+func valid(pattern []int) bool {
+	if len(pattern) == 0 || pattern[0] != 0 {
+		return false
+	}
+	for i := 1; i < len(pattern); i++ {
+		if pattern[i] <= pattern[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// covering returns the smallest prime whose residue classes are all occupied by the
+// pattern's offsets, or 0 if there is none and the pattern is admissible.
+func covering(pattern []int) int {
+	for p := 2; p <= len(pattern); p++ {
+		if !isPrime64(uint64(p)) {
+			continue
+		}
+		seen := make([]bool, p)
+		classes := 0
+		for _, d := range pattern {
+			if r := d % p; !seen[r] {
+				seen[r] = true
+				classes++
+			}
+		}
+		if classes == p {
+			return p
+		}
+	}
+	return 0
+}
+
+// Constellations returns an iterator over each n in [lo, hi] for which n+pattern[i] is
+// prime for every i. Twin primes are {0, 2}, prime triplets {0, 2, 6} and {0, 4, 6}.
+// Inside the sieve, whole words of the packed table are shifted and combined so that
+// sixty-four candidates are examined at once; beyond it the same is done in segments.
+// An inadmissible pattern, such as {0, 2, 4}, must contain the prime that it covers, so
+// its few instances are found quickly; [3, 5, 7] is the only one for {0, 2, 4}. Patterns
+// not beginning with 0 and strictly increasing match nothing.
+func (sieve *Sieve) Constellations(pattern []int, lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if !valid(pattern) {
+			return
+		}
+		if lo <= 2 && 2 <= hi && sieve.matches(pattern, 2) && !yield(2) {
+			return
+		}
+		sieve.scan(pattern, lo, hi, func(seg *segment, base, m uint64) bool {
+			for ; m != 0; m &= m - 1 {
+				n := base + 2*uint64(bits.TrailingZeros64(m))
+				if (seg.exact || sieve.matches(pattern, n)) && !yield(int(n)) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// CountConstellations returns the number of n in [lo, hi] for which n+pattern[i] is prime
+// for every i. See Constellations for the form of pattern.
+func (sieve *Sieve) CountConstellations(pattern []int, lo, hi int) int {
+	if !valid(pattern) {
+		return 0
+	}
+	count := 0
+	if lo <= 2 && 2 <= hi && sieve.matches(pattern, 2) {
+		count++
+	}
+	sieve.scan(pattern, lo, hi, func(seg *segment, base, m uint64) bool {
+		if seg.exact {
+			count += bits.OnesCount64(m)
+			return true
+		}
+		for ; m != 0; m &= m - 1 {
+			if sieve.matches(pattern, base+2*uint64(bits.TrailingZeros64(m))) {
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+// matches tests an individual candidate for the pattern.
+func (sieve *Sieve) matches(pattern []int, n uint64) bool {
+	for _, d := range pattern {
+		if !sieve.isPrime(n + uint64(d)) {
+			return false
+		}
+	}
+	return true
+}
+
+// scanWidth is the number of odd starts per segment when scanning beyond the sieve.
+const scanWidth = 1 << 18
+
+// scan visits the odd starts of a valid pattern in [lo, hi] sixty-four at a time. Each
+// visit receives a segment and a mask whose bit j marks base+2*j as a candidate. The
+// candidates are certain when seg.exact and must be confirmed otherwise. Visiting stops
+// when visit returns false.
+func (sieve *Sieve) scan(pattern []int, lo, hi int, visit func(seg *segment, base, m uint64) bool) {
+	if p := covering(pattern); p != 0 && p < hi {
+		hi = p // every instance contains p
+	}
+	if lo < 3 {
+		lo = 3
+	}
+	if hi < lo {
+		return
+	}
+	span := uint64(pattern[len(pattern)-1])
+	start, end := uint64(lo)|1, uint64(hi)
+
+	// run visits the starts in [start, last] using seg, which must reach last+span.
+	run := func(seg *segment, last uint64) bool {
+		for base := start; base <= last; base += 128 {
+			m := ^uint64(0)
+			for _, d := range pattern {
+				m &= seg.survivors(base + uint64(d))
+			}
+			if count := (last-base)/2 + 1; count < 64 {
+				m &= 1<<count - 1
+			}
+			if m != 0 && !visit(seg, base, m) {
+				return false
+			}
+		}
+		start = last + 2
+		return true
+	}
+
+	if size := uint64(sieve.size); size >= span+3 && start <= size-span { // inside the sieve
+		last := min(end, size-span)
+		if !run(sieve.whole(), last-(last-start)%2) {
+			return
+		}
+	}
+	for start <= end { // beyond the sieve
+		last := min(end, start+2*(scanWidth-1))
+		if !run(sieve.newSegment(start, last+span, 0), last-(last-start)%2) {
+			return
+		}
+	}
+}
+
+// primes returns an iterator over the primes in [lo, hi], a constellation of one.
+func (sieve *Sieve) primes(lo, hi int) iter.Seq[int] {
+	return sieve.Constellations([]int{0}, lo, hi)
+}
@@ -0,0 +1,189 @@
+package sieve
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+var admissibleTests = []struct {
+	pattern    []int
+	admissible bool
+}{
+	{[]int{0}, true},
+	{[]int{0, 1}, false},
+	{[]int{0, 2}, true},
+	{[]int{0, 2, 4}, false},
+	{[]int{0, 2, 6}, true},
+	{[]int{0, 4, 6}, true},
+	{[]int{0, 2, 6, 8}, true},
+	{[]int{0, 2, 6, 8, 12}, true},
+	{[]int{0, 4, 6, 10, 12, 16}, true},
+	{[]int{0, 2, 6, 8, 12, 14}, false}, // covers every class mod 5
+	{[]int{2, 4}, false},
+	{[]int{0, 6, 2}, false},
+	{nil, false},
+}
+
+func TestAdmissible(t *testing.T) {
+	for i, a := range admissibleTests {
+		if admissible := Admissible(a.pattern); admissible != a.admissible {
+			t.Errorf("#%d, Admissible(%v) = %v; want %v", i, a.pattern, admissible, a.admissible)
+		}
+	}
+}
+
+// Do constellation counts reproduce the hand-coded twin and triple tests, both inside the
+// sieve and, with a tiny sieve, entirely in segments?
+func TestCountConstellations(t *testing.T) {
+	tables := []struct {
+		pattern []int
+		rows    []struct{ size, count int }
+	}{
+		{[]int{0, 2}, nil},
+		{[]int{0, 2, 4}, nil},
+		{[]int{0, 2, 6}, nil},
+		{[]int{0, 4, 6}, nil},
+	}
+	for _, a := range twinTests {
+		tables[0].rows = append(tables[0].rows, struct{ size, count int }{a.size, a.twins})
+	}
+	for _, a := range triple024Tests {
+		tables[1].rows = append(tables[1].rows, struct{ size, count int }{a.size, a.triples})
+	}
+	for _, a := range triple026Tests {
+		tables[2].rows = append(tables[2].rows, struct{ size, count int }{a.size, a.triples})
+	}
+	for _, a := range triple046Tests {
+		tables[3].rows = append(tables[3].rows, struct{ size, count int }{a.size, a.triples})
+	}
+
+	for _, table := range tables {
+		for i, a := range table.rows {
+			sieves := []*Sieve{New(a.size + 6), New(1000)}
+			if a.size <= 100000 {
+				sieves = append(sieves, New(10)) // unsieved windows are slow to confirm
+			}
+			for _, s := range sieves {
+				count := s.CountConstellations(table.pattern, 3, a.size)
+				if count != a.count {
+					t.Errorf("#%d, New(%d).CountConstellations(%v, 3, %d) = %d; want %d",
+						i, s.Size(), table.pattern, a.size, count, a.count)
+				}
+			}
+		}
+	}
+}
+
+// largeConstellations runs the counts of constellationTests beyond 10^9, which take from
+// seconds to many minutes.
+var largeConstellations = flag.Bool("large", false, "count constellations to 10^12")
+
+// constellationTests continues the commented-out rows of twinTests, triple026Tests and
+// triple046Tests, counts from Thomas R. Nicely, http://www.trnicely.net.
+var constellationTests = []struct {
+	pattern []int
+	hi      int
+	count   int
+}{
+	{[]int{0, 2}, 10000000, 58980},
+	{[]int{0, 2}, 100000000, 440312},
+	{[]int{0, 2}, 1000000000, 3424506},
+	{[]int{0, 2}, 10000000000, 27412679},
+	{[]int{0, 2}, 100000000000, 224376048},
+	{[]int{0, 2}, 1000000000000, 1870585220},
+	{[]int{0, 2, 6}, 10000000, 8543},
+	{[]int{0, 2, 6}, 100000000, 55600},
+	{[]int{0, 2, 6}, 1000000000, 379508},
+	{[]int{0, 2, 6}, 10000000000, 2713347},
+	{[]int{0, 2, 6}, 100000000000, 20093124},
+	{[]int{0, 2, 6}, 1000000000000, 152850135},
+	{[]int{0, 4, 6}, 10000000, 8677},
+	{[]int{0, 4, 6}, 100000000, 55556},
+	{[]int{0, 4, 6}, 1000000000, 379748},
+	{[]int{0, 4, 6}, 10000000000, 2712226},
+	{[]int{0, 4, 6}, 100000000000, 20081601},
+	{[]int{0, 4, 6}, 1000000000000, 152839134},
+}
+
+// Do segmented counts reach the published counts of twins and triples? Counts beyond
+// 10^8 run only with -large.
+func TestCountConstellationsLarge(t *testing.T) {
+	s := New(1 << 20)
+	for i, a := range constellationTests {
+		if a.hi > 100000000 && !*largeConstellations {
+			continue
+		}
+		if count := s.CountConstellations(a.pattern, 3, a.hi); count != a.count {
+			t.Errorf("#%d, CountConstellations(%v, 3, %d) = %d; want %d", i, a.pattern, a.hi, count, a.count)
+		}
+	}
+}
+
+// Do Constellations and CountConstellations agree with direct testing over odd ranges?
+func TestConstellations(t *testing.T) {
+	patterns := [][]int{{0}, {0, 1}, {0, 2}, {0, 2, 6, 8}, {0, 6, 12, 18}, {0, 2, 4}, {0, 4, 6, 10, 12, 16}}
+	check := New(200000)
+	for _, pattern := range patterns {
+		for _, r := range []struct{ lo, hi int }{{0, 100}, {1, 1000}, {97, 131}, {1000, 150000}, {150000, 150000}} {
+			var want []int
+			for n := r.lo; n <= r.hi; n++ {
+				if check.matches(pattern, uint64(max(n, 0))) {
+					want = append(want, n)
+				}
+			}
+			for _, s := range []*Sieve{check, New(1000), New(0)} {
+				got := slices.Collect(s.Constellations(pattern, r.lo, r.hi))
+				if !slices.Equal(got, want) {
+					t.Errorf("New(%d).Constellations(%v, %d, %d) = %v; want %v", s.Size(), pattern, r.lo, r.hi, got, want)
+				}
+				if count := s.CountConstellations(pattern, r.lo, r.hi); count != len(want) {
+					t.Errorf("New(%d).CountConstellations(%v, %d, %d) = %d; want %d", s.Size(), pattern, r.lo, r.hi, count, len(want))
+				}
+			}
+		}
+	}
+}
+
+// Can twins be counted in a window far beyond a modest sieve?
+func TestConstellationsBeyond(t *testing.T) {
+	s := New(1 << 20)
+	lo, hi := 1000000000000, 1000000100000
+	count := 0
+	for n := lo | 1; n <= hi; n += 2 {
+		if isPrime64(uint64(n)) && isPrime64(uint64(n+2)) {
+			count++
+		}
+	}
+	if got := s.CountConstellations([]int{0, 2}, lo, hi); got != count {
+		t.Errorf("CountConstellations({0, 2}, %d, %d) = %d; want %d", lo, hi, got, count)
+	}
+}
+
+func benchmarkTwins(b *testing.B, n int) {
+	b.StopTimer()
+	s := New(n + 2)
+	b.StartTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.CountConstellations([]int{0, 2}, 0, n)
+	}
+}
+
+func BenchmarkTwins1000000(b *testing.B)  { benchmarkTwins(b, 1000000) }
+func BenchmarkTwins10000000(b *testing.B) { benchmarkTwins(b, 10000000) }
+
+func ExampleSieve_Constellations() {
+	// Find the prime quadruplets below 1000.
+	s := New(1000)
+	for n := range s.Constellations([]int{0, 2, 6, 8}, 0, 1000) {
+		fmt.Println(n, n+2, n+6, n+8)
+	}
+	// Output:
+	// 5 7 11 13
+	// 11 13 17 19
+	// 101 103 107 109
+	// 191 193 197 199
+	// 821 823 827 829
+}
@@ -0,0 +1,310 @@
+package sieve
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// The Cunningham project (Cunningham and Woodall, 1925; Brillhart et al., 1983 on)
+// factors b^n±1 for small bases. Algebra does the first part of the work: b^n-1 is the
+// product of the cyclotomic values Φ_d(b) for d dividing n, and b^n+1 the product of
+// those for d dividing 2n but not n. When b = s·y² with s squarefree, and d is an odd
+// multiple of s (or of 2s if s is not 1 mod 4), Aurifeuille's identity splits Φ_d(b)
+// into two pieces L and M of about equal size.
+
+// Status values of a CunninghamFactor.
+const (
+	StatusPrime     = "P"   // proven prime, by a deterministic test or a certificate
+	StatusPRP       = "PRP" // probable prime that could not be certified
+	StatusComposite = "C"   // composite that resisted factoring
+)
+
+// CunninghamFactor is a factor of b^n±1 with its multiplicity and status.
+type CunninghamFactor struct {
+	Factor *big.Int
+	Count  int
+	Status string
+}
+
+// CunninghamPiece is an algebraic factor of b^n±1, the cyclotomic value Φ_d(b) or one of
+// its Aurifeuillian parts L_d(b) and M_d(b), with its factorization.
+type CunninghamPiece struct {
+	Name    string
+	D       int
+	Value   *big.Int
+	Factors []CunninghamFactor
+}
+
+// Cunningham is the factorization of B^N+Sign, for Sign = ±1, piece by piece.
+type Cunningham struct {
+	B, N, Sign int
+	Pieces     []CunninghamPiece
+}
+
+// aurifeuilleReach bounds the squarefree part of bases given Aurifeuillian splits.
+const aurifeuilleReach = 1 << 10
+
+// FactorCunningham factors b^n+sign, for sign = ±1, as the Cunningham tables do: into
+// cyclotomic pieces, split further by Aurifeuille's identities where they apply, each
+// factored by FactorBig. Prime factors are certified where possible. It returns nil for
+// b < 2, n < 1, a sign other than ±1, or b or 2n beyond the sieve's reach.
+func (sieve *Sieve) FactorCunningham(b, n, sign int) *Cunningham {
+	if b < 2 || n < 1 || (sign != 1 && sign != -1) || !sieve.reaches(b) || !sieve.reaches(2*n) {
+		return nil
+	}
+	c := &Cunningham{B: b, N: n, Sign: sign}
+	B := big.NewInt(int64(b))
+	name := func(p string, d int) string { return fmt.Sprintf("%s_%d(%d)", p, d, b) }
+	m := n
+	if sign == 1 {
+		m = 2 * n
+	}
+	for d := 1; d <= m; d++ {
+		if m%d != 0 || (sign == 1 && n%d == 0) {
+			continue
+		}
+		phi := sieve.Cyclotomic(d, B)
+		if l, r := sieve.aurifeuillian(b, d, phi); l != nil {
+			c.Pieces = append(c.Pieces, sieve.piece(name("L", d), d, l), sieve.piece(name("M", d), d, r))
+		} else {
+			c.Pieces = append(c.Pieces, sieve.piece(name("Φ", d), d, phi))
+		}
+	}
+	return c
+}
+
+// piece factors one algebraic factor.
+func (sieve *Sieve) piece(name string, d int, value *big.Int) CunninghamPiece {
+	p := CunninghamPiece{Name: name, D: d, Value: value}
+	if value.Cmp(bigOne) == 0 {
+		return p
+	}
+	for _, f := range sieve.FactorBig(value) {
+		status := StatusComposite
+		switch {
+		case !f.Prime:
+		case f.Factor.IsUint64():
+			status = StatusPrime
+		default:
+			status = StatusPRP
+			if _, err := sieve.CertifyBig(f.Factor); err == nil {
+				status = StatusPrime
+			}
+		}
+		p.Factors = append(p.Factors, CunninghamFactor{f.Factor, f.Count, status})
+	}
+	return p
+}
+
+// Value returns B^N+Sign.
+func (c *Cunningham) Value() *big.Int {
+	v := new(big.Int).Exp(big.NewInt(int64(c.B)), big.NewInt(int64(c.N)), nil)
+	return v.Add(v, big.NewInt(int64(c.Sign)))
+}
+
+// Factors returns the factors of all pieces in ascending order, with those shared by
+// several pieces merged.
+func (c *Cunningham) Factors() []CunninghamFactor {
+	var all []CunninghamFactor
+	for _, p := range c.Pieces {
+		all = append(all, p.Factors...)
+	}
+	slices.SortFunc(all, func(a, b CunninghamFactor) int { return a.Factor.Cmp(b.Factor) })
+	var result []CunninghamFactor
+	for _, f := range all {
+		if k := len(result) - 1; k >= 0 && result[k].Factor.Cmp(f.Factor) == 0 {
+			result[k].Count += f.Count
+			continue
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// Complete reports whether every factor is a proven prime.
+func (c *Cunningham) Complete() bool {
+	for _, f := range c.Factors() {
+		if f.Status != StatusPrime {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTable writes the factorization as a table with one row per piece: its name, its
+// number of digits, and its factors, each marked with its status unless proven prime.
+func (c *Cunningham) WriteTable(w io.Writer) error {
+	sign := "+"
+	if c.Sign < 0 {
+		sign = "-"
+	}
+	if _, err := fmt.Fprintf(w, "%d^%d%s1\n%-12s %6s  %s\n", c.B, c.N, sign, "piece", "digits", "factors"); err != nil {
+		return err
+	}
+	for _, p := range c.Pieces {
+		var s []string
+		for _, f := range p.Factors {
+			t := f.Factor.String()
+			if f.Count > 1 {
+				t += fmt.Sprintf("^%d", f.Count)
+			}
+			if f.Status != StatusPrime {
+				t += "[" + f.Status + "]"
+			}
+			s = append(s, t)
+		}
+		if _, err := fmt.Fprintf(w, "%-12s %6d  %s\n", p.Name, len(p.Value.String()), strings.Join(s, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cyclotomic returns Φ_n(x), the nth cyclotomic polynomial at x >= 2, as the product of
+// (x^d-1)^μ(n/d) over the divisors d of n. It returns nil for smaller x or for n < 1 or
+// beyond the sieve's reach.
+func (sieve *Sieve) Cyclotomic(n int, x *big.Int) *big.Int {
+	if n < 1 || !sieve.reaches(n) || x.Cmp(bigOne) <= 0 {
+		return nil
+	}
+	num, den := big.NewInt(1), big.NewInt(1)
+	var t big.Int
+	for d := 1; d <= n; d++ {
+		if n%d != 0 {
+			continue
+		}
+		t.Exp(x, big.NewInt(int64(d)), nil).Sub(&t, bigOne)
+		switch sieve.Mobius(n / d) {
+		case 1:
+			num.Mul(num, &t)
+		case -1:
+			den.Mul(den, &t)
+		}
+	}
+	return num.Quo(num, den)
+}
+
+// aurifeuillian splits phi = Φ_d(b) into its Aurifeuillian parts, or returns nils if
+// none apply or the split is trivial. With b = s·y², and d = k·d₀ for odd k, where d₀
+// is s or 2s as s is or is not 1 mod 4, Φ_d₀(x) = C(x)² - s·x·D(x)². Writing b^k = s·Y²,
+// Φ_d₀(b^k) = (C - sYD)(C + sYD) at x = b^k, and Φ_d(b) divides it; the GCDs of Φ_d(b)
+// with the two factors are L_d(b) and M_d(b).
+func (sieve *Sieve) aurifeuillian(b, d int, phi *big.Int) (l, m *big.Int) {
+	s, y := 1, 1
+	for _, f := range sieve.FactorUnique(b) {
+		for range f.Count / 2 {
+			y *= f.Factor
+		}
+		if f.Count%2 == 1 {
+			s *= f.Factor
+		}
+	}
+	d0 := s
+	if s%4 != 1 {
+		d0 = 2 * s
+	}
+	if s == 1 || s > aurifeuilleReach || d%d0 != 0 || d/d0%2 == 0 {
+		return nil, nil
+	}
+	k := d / d0
+	C, D := aurifeuille(s)
+
+	S := big.NewInt(int64(s))
+	Y := new(big.Int).Exp(big.NewInt(int64(y)), big.NewInt(int64(k)), nil)
+	Y.Mul(Y, new(big.Int).Exp(S, big.NewInt(int64(k-1)/2), nil))
+	X := new(big.Int).Mul(Y, Y)
+	X.Mul(X, S)
+	horner := func(c []*big.Int) *big.Int {
+		v := new(big.Int)
+		for i := len(c) - 1; i >= 0; i-- {
+			v.Mul(v, X).Add(v, c[i])
+		}
+		return v
+	}
+	cx, sy := horner(C), horner(D)
+	sy.Mul(sy, Y).Mul(sy, S)
+
+	l = new(big.Int).GCD(nil, nil, phi, new(big.Int).Sub(cx, sy))
+	if l.Cmp(bigOne) == 0 || l.Cmp(phi) == 0 {
+		return nil, nil
+	}
+	return l, new(big.Int).Quo(phi, l)
+}
+
+// aurifeuille returns the coefficients, constant first, of the polynomials C and D with
+// Φ_d₀(x) = C(x)² - s·x·D(x)² for squarefree s > 1 and d₀ as in aurifeuillian. With
+// x = t², F(t) = C(t²) - √s·t·D(t²) is the product of t - ω^j, ω = e^(πi/d₀), over the
+// j coprime to d₀ mod 2d₀ that are odd with Kronecker symbol (δ/j) = ε or even with
+// (δ/(j+d₀)) = -ε, where δ is the discriminant of Q(√s) and ε the sign that makes the
+// sum of the roots +√s (Stevenhagen, 1987; Brent, 1993). The power sums of those roots
+// are integers for even powers and integer multiples of √s for odd ones, so they are
+// computed in floating point and rounded, and Newton's identities give the coefficients.
+func aurifeuille(s int) (C, D []*big.Int) {
+	d0, delta := s, s
+	if s%4 != 1 {
+		d0, delta = 2*s, 4*s
+	}
+	var roots [2][]int
+	for j := 1; j < 2*d0; j++ {
+		if gcd(j, d0) != 1 {
+			continue
+		}
+		r := jacobi(uint64(delta), uint64(j))
+		if j%2 == 0 {
+			r = -jacobi(uint64(delta), uint64(j+d0))
+		}
+		roots[(1-r)/2] = append(roots[(1-r)/2], j) // ε = 1, then ε = -1
+	}
+
+	phi := len(roots[0])
+	power := func(S []int, m int) int64 {
+		sum := 0.0
+		for _, j := range S {
+			sum += math.Cos(math.Pi * float64(j*m%(2*d0)) / float64(d0))
+		}
+		if m%2 == 1 {
+			sum /= math.Sqrt(float64(s))
+		}
+		return int64(math.Round(sum))
+	}
+	S := roots[0]
+	if power(S, 1) != 1 {
+		S = roots[1]
+	}
+	P := make([]*big.Int, phi+1)
+	for m := 1; m <= phi; m++ {
+		P[m] = big.NewInt(power(S, m))
+	}
+
+	// e_k is the kth elementary symmetric function of the roots, an integer for even k
+	// and an integer multiple of √s, kept without the √s, for odd k.
+	e := []*big.Int{big.NewInt(1)}
+	var t big.Int
+	for k := 1; k <= phi; k++ {
+		sum := new(big.Int)
+		for i := 1; i <= k; i++ {
+			t.Mul(e[k-i], P[i])
+			if (k-i)%2 == 1 && i%2 == 1 {
+				t.Mul(&t, big.NewInt(int64(s)))
+			}
+			if i%2 == 1 {
+				sum.Add(sum, &t)
+			} else {
+				sum.Sub(sum, &t)
+			}
+		}
+		e = append(e, sum.Quo(sum, big.NewInt(int64(k))))
+	}
+	for k := 0; k <= phi; k++ {
+		if k%2 == 0 {
+			C = append(C, e[k])
+		} else {
+			D = append(D, e[k])
+		}
+	}
+	return C, D
+}
@@ -0,0 +1,145 @@
+package sieve
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+)
+
+func TestCyclotomic(t *testing.T) {
+	sieve := New(1000)
+	for i, a := range []struct {
+		n, x int
+		want string
+	}{
+		{1, 7, "6"}, {2, 7, "8"}, {12, 10, "9901"}, {30, 3, "8401"}, {105, 2, "473474689919911"},
+	} {
+		if have := sieve.Cyclotomic(a.n, big.NewInt(int64(a.x))); have == nil || have.String() != a.want {
+			t.Errorf("#%d, Cyclotomic(%d, %d) = %v; want %s", i, a.n, a.x, have, a.want)
+		}
+	}
+	if v := sieve.Cyclotomic(0, big.NewInt(2)); v != nil {
+		t.Errorf("Cyclotomic(0, 2) = %v", v)
+	}
+	if v := sieve.Cyclotomic(3, big.NewInt(1)); v != nil {
+		t.Errorf("Cyclotomic(3, 1) = %v", v)
+	}
+}
+
+func TestAurifeuille(t *testing.T) {
+	sieve := New(1000)
+	for _, s := range []int{2, 3, 5, 6, 7, 10, 11, 13, 14, 15, 17, 19, 21, 22, 23, 101, 210, 1009} {
+		C, D := aurifeuille(s)
+		d0 := s
+		if s%4 != 1 {
+			d0 = 2 * s
+		}
+		for x := int64(2); x < 6; x++ {
+			X := big.NewInt(x)
+			eval := func(c []*big.Int) *big.Int {
+				v := new(big.Int)
+				for i := len(c) - 1; i >= 0; i-- {
+					v.Mul(v, X).Add(v, c[i])
+				}
+				return v
+			}
+			c, d := eval(C), eval(D)
+			d.Mul(d, d).Mul(d, X).Mul(d, big.NewInt(int64(s)))
+			if c.Mul(c, c).Sub(c, d).Cmp(sieve.Cyclotomic(d0, X)) != 0 {
+				t.Errorf("s = %d: C(%d)² - %d·%d·D(%d)² != Φ_%d(%d)", s, x, s, x, x, d0, x)
+			}
+		}
+	}
+}
+
+// Aurifeuillian splits with closed forms:
+// 2^2k+1 = (2^k - 2^((k+1)/2) + 1)(2^k + 2^((k+1)/2) + 1) and
+// 3^3k+1 = (3^k+1)(3^k - 3^((k+1)/2) + 1)(3^k + 3^((k+1)/2) + 1), both for odd k.
+// (For k = 3 the first factor of 2^6+1 is 5, which divides 2^2+1 instead.)
+func TestAurifeuillian(t *testing.T) {
+	sieve := New(1000)
+	for k := 5; k < 60; k += 2 {
+		for _, a := range []struct{ b, e int }{{2, 2}, {3, 3}} {
+			B := big.NewInt(int64(a.b))
+			d := 2 * a.e * k
+			phi := sieve.Cyclotomic(d, B)
+			l, m := sieve.aurifeuillian(a.b, d, phi)
+			if l == nil {
+				t.Errorf("no split of Φ_%d(%d)", d, a.b)
+				continue
+			}
+			bk := new(big.Int).Exp(B, big.NewInt(int64(k)), nil)
+			h := new(big.Int).Exp(B, big.NewInt(int64(k+1)/2), nil)
+			lo := new(big.Int).Sub(bk, h)
+			lo.Add(lo, bigOne)
+			if new(big.Int).Mul(l, m).Cmp(phi) != 0 || new(big.Int).Rem(lo, l).Sign() != 0 {
+				t.Errorf("Φ_%d(%d) split as %v · %v", d, a.b, l, m)
+			}
+		}
+	}
+}
+
+var cunninghamTests = []struct {
+	b, n, sign int
+	pieces     []string
+}{
+	{2, 58, 1, []string{"Φ_4(2)", "L_116(2)", "M_116(2)"}},
+	{2, 64, 1, []string{"Φ_128(2)"}},
+	{2, 12, -1, []string{"Φ_1(2)", "Φ_2(2)", "Φ_3(2)", "Φ_4(2)", "Φ_6(2)", "Φ_12(2)"}},
+	{10, 30, 1, []string{"Φ_4(10)", "Φ_12(10)", "L_20(10)", "M_20(10)", "L_60(10)", "M_60(10)"}},
+	{5, 15, -1, []string{"Φ_1(5)", "Φ_3(5)", "L_5(5)", "M_5(5)", "L_15(5)", "M_15(5)"}},
+	{3, 27, 1, []string{"Φ_2(3)", "Φ_6(3)", "L_18(3)", "M_18(3)", "L_54(3)", "M_54(3)"}},
+	{12, 18, -1, []string{"Φ_1(12)", "Φ_2(12)", "Φ_3(12)", "L_6(12)", "M_6(12)", "Φ_9(12)", "L_18(12)", "M_18(12)"}},
+}
+
+func TestFactorCunningham(t *testing.T) {
+	sieve := New(1 << 16)
+	for i, a := range cunninghamTests {
+		c := sieve.FactorCunningham(a.b, a.n, a.sign)
+		var names []string
+		pieces, product := big.NewInt(1), big.NewInt(1)
+		for _, p := range c.Pieces {
+			names = append(names, p.Name)
+			pieces.Mul(pieces, p.Value)
+		}
+		if fmt.Sprint(names) != fmt.Sprint(a.pieces) {
+			t.Errorf("#%d, %d^%d%+d pieces %v; want %v", i, a.b, a.n, a.sign, names, a.pieces)
+		}
+		for _, f := range c.Factors() {
+			product.Mul(product, new(big.Int).Exp(f.Factor, big.NewInt(int64(f.Count)), nil))
+			if !f.Factor.ProbablyPrime(20) {
+				t.Errorf("#%d, %d^%d%+d factor %v is not prime", i, a.b, a.n, a.sign, f.Factor)
+			}
+		}
+		if v := c.Value(); pieces.Cmp(v) != 0 || product.Cmp(v) != 0 {
+			t.Errorf("#%d, %d^%d%+d = %v; pieces multiply to %v, factors to %v", i, a.b, a.n, a.sign, v, pieces, product)
+		}
+		if !c.Complete() {
+			t.Errorf("#%d, %d^%d%+d incomplete", i, a.b, a.n, a.sign)
+		}
+	}
+	for _, a := range [][3]int{{1, 5, 1}, {2, 0, 1}, {2, 5, 0}} {
+		if c := sieve.FactorCunningham(a[0], a[1], a[2]); c != nil {
+			t.Errorf("FactorCunningham(%d, %d, %d) = %+v", a[0], a[1], a[2], c)
+		}
+	}
+}
+
+func BenchmarkFactorCunningham(b *testing.B) {
+	sieve := New(1 << 16)
+	for b.Loop() {
+		sieve.FactorCunningham(10, 30, 1)
+	}
+}
+
+func ExampleCunningham_WriteTable() {
+	sieve := New(1 << 16)
+	sieve.FactorCunningham(2, 58, 1).WriteTable(os.Stdout)
+	// Output:
+	// 2^58+1
+	// piece        digits  factors
+	// Φ_4(2)            1  5
+	// L_116(2)          9  107367629
+	// M_116(2)          9  536903681
+}
@@ -0,0 +1,272 @@
+package sieve
+
+import (
+	"iter"
+	"math/big"
+	"slices"
+)
+
+// DigitFamilies enumerates primes defined by their digits in a fixed base: palindromic
+// primes, emirps, circular, permutable and truncatable primes, and repunits. Candidates
+// inside the sieve are answered by the table; larger ones by deterministic testing up to
+// 2^64 and by probable-prime testing beyond.
+type DigitFamilies struct {
+	sieve *Sieve
+	base  int
+}
+
+// DigitFamilies returns the families for the given base, which must be in 2..36 as for
+// strconv. It returns nil for other bases.
+func (sieve *Sieve) DigitFamilies(base int) *DigitFamilies {
+	if base < 2 || base > 36 {
+		return nil
+	}
+	return &DigitFamilies{sieve: sieve, base: base}
+}
+
+// Base returns the base in which digits are considered.
+func (f *DigitFamilies) Base() int {
+	return f.base
+}
+
+// digits returns the digits of n, most significant first.
+func (f *DigitFamilies) digits(n uint64) []int {
+	var d []int
+	for ; n > 0; n /= uint64(f.base) {
+		d = append(d, int(n%uint64(f.base)))
+	}
+	slices.Reverse(d)
+	return d
+}
+
+// prime tests the number with the given digits, which may have leading zeros and may
+// exceed 64 bits.
+func (f *DigitFamilies) prime(digits []int) bool {
+	n, b := uint64(0), uint64(f.base)
+	for i, d := range digits {
+		if n > (1<<64-1-uint64(d))/b { // too large for 64 bits
+			x := new(big.Int).SetUint64(n)
+			for _, d := range digits[i:] {
+				x.Mul(x, big.NewInt(int64(b)))
+				x.Add(x, big.NewInt(int64(d)))
+			}
+			return f.sieve.probablyPrime(x)
+		}
+		n = n*b + uint64(d)
+	}
+	return f.sieve.isPrime(n)
+}
+
+// probablyPrime tests x for primality, definitively below 2^64 and with trial division
+// and the Baillie-PSW test beyond.
+func (sieve *Sieve) probablyPrime(x *big.Int) bool {
+	if x.Sign() < 0 {
+		return false
+	}
+	if x.IsUint64() {
+		return sieve.isPrime(x.Uint64())
+	}
+	return sieve.bpswBig(x)
+}
+
+// Palindromic returns an iterator over the primes in [lo, hi] whose digits read the same
+// in both directions (http://oeis.org/A002385 in base 10). Palindromes are generated from
+// their leading halves, so only about sqrt(hi) candidates are tested.
+func (f *DigitFamilies) Palindromic(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if hi < 0 {
+			return
+		}
+		b := uint64(f.base)
+		for length, first := 1, uint64(1); ; length++ { // first is the smallest leading half
+			for half := first; half < first*b; half++ {
+				n, m := half, half
+				if length%2 == 1 {
+					m /= b // the middle digit is not repeated
+				}
+				for ; m > 0; m /= b { // append the leading half's digits reversed
+					if n > uint64(hi)/b {
+						return // this and all later palindromes exceed hi
+					}
+					n = n*b + m%b
+				}
+				if n > uint64(hi) {
+					return
+				}
+				if n >= uint64(max(lo, 0)) && f.sieve.isPrime(n) && !yield(int(n)) {
+					return
+				}
+			}
+			if length%2 == 0 {
+				first *= b
+			}
+		}
+	}
+}
+
+// reverse returns the digits in reverse order.
+func reverse(digits []int) []int {
+	r := slices.Clone(digits)
+	slices.Reverse(r)
+	return r
+}
+
+// Emirps returns an iterator over the primes in [lo, hi] whose digit reversals are
+// different primes (http://oeis.org/A006567 in base 10).
+func (f *DigitFamilies) Emirps(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for p := range f.sieve.primes(lo, hi) {
+			d := f.digits(uint64(p))
+			r := reverse(d)
+			if !slices.Equal(d, r) && f.prime(r) && !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+// Circular returns an iterator over the primes in [lo, hi] all of whose digit rotations
+// are prime (http://oeis.org/A068652 in base 10).
+func (f *DigitFamilies) Circular(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for p := range f.sieve.primes(lo, hi) {
+			d := f.digits(uint64(p))
+			circular := true
+			for i := 1; i < len(d) && circular; i++ {
+				circular = f.prime(append(slices.Clone(d[i:]), d[:i]...))
+			}
+			if circular && !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+// Permutable returns an iterator over the primes in [lo, hi] all of whose digit
+// permutations are prime (http://oeis.org/A003459 in base 10). A permutation ending in a
+// digit sharing a factor with the base is composite, so only numbers whose digits are
+// all coprime to the base are permuted.
+func (f *DigitFamilies) Permutable(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for p := range f.sieve.primes(lo, hi) {
+			d := f.digits(uint64(p))
+			permutable := true
+			if len(d) > 1 {
+				for _, x := range d {
+					if gcd(x, f.base) != 1 {
+						permutable = false
+						break
+					}
+				}
+			}
+			perm := slices.Clone(d)
+			slices.Sort(perm)
+			for more := true; permutable && more; more = nextPermutation(perm) {
+				permutable = f.prime(perm)
+			}
+			if permutable && !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+// gcd returns the greatest common divisor of nonnegative a and b.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// nextPermutation rearranges d into the next lexicographically greater permutation,
+// reporting false when d is already the greatest.
+func nextPermutation(d []int) bool {
+	i := len(d) - 2
+	for i >= 0 && d[i] >= d[i+1] {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+	j := len(d) - 1
+	for d[j] <= d[i] {
+		j--
+	}
+	d[i], d[j] = d[j], d[i]
+	slices.Reverse(d[i+1:])
+	return true
+}
+
+// RightTruncatable returns every prime that remains prime as its last digits are removed
+// one at a time (http://oeis.org/A024770 in base 10), in increasing order. There are
+// finitely many in each base. They are grown a digit at a time from the one-digit primes.
+func (f *DigitFamilies) RightTruncatable() []*big.Int {
+	return f.truncatable(func(n *big.Int, d, length int) *big.Int {
+		m := new(big.Int).Mul(n, big.NewInt(int64(f.base)))
+		return m.Add(m, big.NewInt(int64(d)))
+	})
+}
+
+// LeftTruncatable returns every prime without zero digits that remains prime as its
+// first digits are removed one at a time (http://oeis.org/A024785 in base 10), in
+// increasing order. There are finitely many in each base, though some exceed 2^64; the
+// largest in base 10 has 24 digits.
+func (f *DigitFamilies) LeftTruncatable() []*big.Int {
+	b := big.NewInt(int64(f.base))
+	return f.truncatable(func(n *big.Int, d, length int) *big.Int {
+		if d == 0 {
+			return nil
+		}
+		m := new(big.Int).Exp(b, big.NewInt(int64(length)), nil)
+		m.Mul(m, big.NewInt(int64(d)))
+		return m.Add(m, n)
+	})
+}
+
+// truncatable grows the family from the one-digit primes, extending each member of
+// the given length by digit d as grow directs.
+func (f *DigitFamilies) truncatable(grow func(n *big.Int, d, length int) *big.Int) []*big.Int {
+	var family, current []*big.Int
+	for d := 2; d < f.base; d++ {
+		if f.sieve.isPrime(uint64(d)) {
+			current = append(current, big.NewInt(int64(d)))
+		}
+	}
+	for length := 1; len(current) > 0; length++ {
+		family = append(family, current...)
+		var next []*big.Int
+		for _, n := range current {
+			for d := 0; d < f.base; d++ {
+				if m := grow(n, d, length); m != nil && f.sieve.probablyPrime(m) {
+					next = append(next, m)
+				}
+			}
+		}
+		current = next
+	}
+	slices.SortFunc(family, func(a, b *big.Int) int { return a.Cmp(b) })
+	return family
+}
+
+// Repunit returns the n-digit repunit (base^n-1)/(base-1), whose digits are all 1.
+func (f *DigitFamilies) Repunit(n int) *big.Int {
+	b := big.NewInt(int64(f.base))
+	r := new(big.Int).Exp(b, big.NewInt(int64(n)), nil)
+	r.Sub(r, big.NewInt(1))
+	return r.Div(r, b.Sub(b, big.NewInt(1)))
+}
+
+// RepunitPRPs returns an iterator over the n in [lo, hi] for which the n-digit repunit
+// is a probable prime (http://oeis.org/A004023 in base 10, the Mersenne exponents in
+// base 2). The repunit of composite length ab is divisible by that of length a, so only
+// prime lengths drawn from the sieve are tested.
+func (f *DigitFamilies) RepunitPRPs(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for n := range f.sieve.primes(lo, hi) {
+			if f.sieve.probablyPrime(f.Repunit(n)) && !yield(n) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package sieve
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+var digitTests = []struct {
+	base                                   int
+	hi                                     int
+	palindromic, emirps, circular, permute int   // counts of primes <= hi
+	first                                  []int // first few palindromic primes
+}{
+	{10, 1000000, 113, 11184, 55, 22, []int{2, 3, 5, 7, 11, 101, 131, 151, 181, 191, 313, 353}},
+	{2, 5000, 19, 289, 4, -1, []int{3, 5, 7, 17, 31, 73, 107, 127, 257, 313, 443, 1193}},
+	{16, 5000, 43, 171, 66, -1, []int{2, 3, 5, 7, 11, 13, 17, 257, 337, 353, 401, 433}},
+}
+
+func count(seq func(func(int) bool)) int {
+	n := 0
+	for range seq {
+		n++
+	}
+	return n
+}
+
+func TestDigitFamilies(t *testing.T) {
+	for i, a := range digitTests {
+		for _, s := range []*Sieve{New(a.hi), New(100)} {
+			f := s.DigitFamilies(a.base)
+			if n := count(f.Palindromic(0, a.hi)); n != a.palindromic {
+				t.Errorf("#%d, base %d: %d palindromic primes; want %d", i, a.base, n, a.palindromic)
+			}
+			if n := count(f.Emirps(0, a.hi)); n != a.emirps {
+				t.Errorf("#%d, base %d: %d emirps; want %d", i, a.base, n, a.emirps)
+			}
+			if n := count(f.Circular(0, a.hi)); n != a.circular {
+				t.Errorf("#%d, base %d: %d circular primes; want %d", i, a.base, n, a.circular)
+			}
+			if n := count(f.Permutable(0, a.hi)); a.permute >= 0 && n != a.permute {
+				t.Errorf("#%d, base %d: %d permutable primes; want %d", i, a.base, n, a.permute)
+			}
+			first := slices.Collect(f.Palindromic(0, a.first[len(a.first)-1]))
+			if !slices.Equal(first, a.first) {
+				t.Errorf("#%d, base %d: palindromic primes %v; want %v", i, a.base, first, a.first)
+			}
+		}
+	}
+	if f := New(10).DigitFamilies(37); f != nil {
+		t.Errorf("DigitFamilies(37) = %v; want nil", f)
+	}
+}
+
+func TestPalindromicRange(t *testing.T) {
+	f := New(100).DigitFamilies(10)
+	want := []int{1003001, 1008001, 1022201, 1028201, 1035301}
+	if got := slices.Collect(f.Palindromic(1000000, 1040000)); !slices.Equal(got, want) {
+		t.Errorf("Palindromic(10^6, 1040000) = %v; want %v", got, want)
+	}
+	if got := slices.Collect(f.Palindromic(0, -1)); len(got) != 0 {
+		t.Errorf("Palindromic(0, -1) = %v", got)
+	}
+}
+
+var truncatableTests = []struct {
+	base         int
+	right        int    // number of right-truncatable primes
+	largestRight string // the largest
+	left         int    // number of left-truncatable primes
+	largestLeft  string
+}{
+	{10, 83, "73939133", 4260, "357686312646216567629137"},
+	{7, 19, "6841", 22, "817337"},
+	{3, 4, "71", 3, "23"},
+	{2, 0, "", 0, ""},
+}
+
+// largest formats the last of an ascending family, or "" if it is empty.
+func largest(family []*big.Int) string {
+	if len(family) == 0 {
+		return ""
+	}
+	return family[len(family)-1].String()
+}
+
+func TestTruncatable(t *testing.T) {
+	for i, a := range truncatableTests {
+		f := New(1000).DigitFamilies(a.base)
+		right, left := f.RightTruncatable(), f.LeftTruncatable()
+		if len(right) != a.right || largest(right) != a.largestRight {
+			t.Errorf("#%d, base %d: %d right-truncatable primes up to %s; want %d up to %s",
+				i, a.base, len(right), largest(right), a.right, a.largestRight)
+		}
+		if len(left) != a.left || largest(left) != a.largestLeft {
+			t.Errorf("#%d, base %d: %d left-truncatable primes up to %s; want %d up to %s",
+				i, a.base, len(left), largest(left), a.left, a.largestLeft)
+		}
+	}
+}
+
+func TestRepunitPRPs(t *testing.T) {
+	s := New(1000)
+	tests := []struct {
+		base int
+		hi   int
+		want []int
+	}{
+		{10, 200, []int{2, 19, 23}},                                   // http://oeis.org/A004023
+		{2, 130, []int{2, 3, 5, 7, 13, 17, 19, 31, 61, 89, 107, 127}}, // Mersenne exponents
+		{3, 100, []int{3, 7, 13, 71}},                                 // http://oeis.org/A028491
+	}
+	for i, a := range tests {
+		if got := slices.Collect(s.DigitFamilies(a.base).RepunitPRPs(0, a.hi)); !slices.Equal(got, a.want) {
+			t.Errorf("#%d, base %d: repunit primes %v; want %v", i, a.base, got, a.want)
+		}
+	}
+	if r := s.DigitFamilies(16).Repunit(3); r.Int64() != 0x111 {
+		t.Errorf("Repunit(3) in base 16 = %v; want %d", r, 0x111)
+	}
+}
+
+func ExampleDigitFamilies_Circular() {
+	// Find the circular primes below 1000.
+	f := New(1000).DigitFamilies(10)
+	fmt.Println(slices.Collect(f.Circular(0, 1000)))
+	// Output:
+	// [2 3 5 7 11 13 17 31 37 71 73 79 97 113 131 197 199 311 337 373 719 733 919 971 991]
+}
@@ -0,0 +1,121 @@
+package sieve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Gap describes the gap between consecutive primes P and P+Size.
+type Gap struct {
+	P     int     `json:"p"`     // the prime preceding the gap
+	Size  int     `json:"size"`  // distance to the next prime
+	Merit float64 `json:"merit"` // Size/ln(P), the gap relative to the average near P
+	CSG   float64 `json:"csg"`   // Size/ln²(P), the Cramér-Shanks-Granville ratio
+}
+
+// newGap measures the gap following prime p.
+func newGap(p, size int) Gap {
+	log := math.Log(float64(p))
+	return Gap{P: p, Size: size, Merit: float64(size) / log, CSG: float64(size) / (log * log)}
+}
+
+// GapCount is one bar of the gap histogram.
+type GapCount struct {
+	Size  int `json:"size"`
+	Count int `json:"count"`
+}
+
+// GapStats summarizes the gaps between consecutive primes in a range.
+type GapStats struct {
+	Lo           int        `json:"lo"`           // first number examined
+	Hi           int        `json:"hi"`           // last number examined
+	Primes       int        `json:"primes"`       // primes in [Lo, Hi]
+	Distribution []GapCount `json:"distribution"` // occurrences of each gap, ascending by size
+	First        []Gap      `json:"first"`        // first occurrence of each gap, ascending by size
+	Maximal      []Gap      `json:"maximal"`      // gaps larger than all before them, in order
+	MaxMerit     Gap        `json:"maxMerit"`     // the gap of greatest merit
+	MaxCSG       Gap        `json:"maxCSG"`       // the gap of greatest Cramér-Shanks-Granville ratio
+}
+
+// Gaps walks the primes of [lo, hi] once, in the table where it can and in segments
+// where it must, and gathers statistics on the gaps between consecutive primes. Only
+// gaps with both ends in the range are counted, so maximal gaps are the classical record
+// gaps (http://oeis.org/A002386) when lo <= 2, and records within the range otherwise.
+// The smallest primes inflate merit and CSG ratio, so MaxMerit and MaxCSG consider gaps
+// following primes p >= 11 only.
+func (sieve *Sieve) Gaps(lo, hi int) *GapStats {
+	stats := &GapStats{Lo: lo, Hi: hi}
+	counts := make(map[int]int)
+	first := make(map[int]Gap)
+	prev := 0
+	for p := range sieve.primes(lo, hi) {
+		stats.Primes++
+		if prev == 0 {
+			prev = p
+			continue
+		}
+		gap := newGap(prev, p-prev)
+		if counts[gap.Size] == 0 {
+			first[gap.Size] = gap
+			if len(stats.Maximal) == 0 || gap.Size > stats.Maximal[len(stats.Maximal)-1].Size {
+				stats.Maximal = append(stats.Maximal, gap)
+			}
+		}
+		counts[gap.Size]++
+		if prev >= 11 && gap.Merit > stats.MaxMerit.Merit {
+			stats.MaxMerit = gap
+		}
+		if prev >= 11 && gap.CSG > stats.MaxCSG.CSG {
+			stats.MaxCSG = gap
+		}
+		prev = p
+	}
+
+	for size, count := range counts {
+		stats.Distribution = append(stats.Distribution, GapCount{size, count})
+		stats.First = append(stats.First, first[size])
+	}
+	sort.Slice(stats.Distribution, func(i, j int) bool { return stats.Distribution[i].Size < stats.Distribution[j].Size })
+	sort.Slice(stats.First, func(i, j int) bool { return stats.First[i].Size < stats.First[j].Size })
+	return stats
+}
+
+// Count returns the number of gaps of the given size.
+func (stats *GapStats) Count(size int) int {
+	i := sort.Search(len(stats.Distribution), func(i int) bool { return stats.Distribution[i].Size >= size })
+	if i < len(stats.Distribution) && stats.Distribution[i].Size == size {
+		return stats.Distribution[i].Count
+	}
+	return 0
+}
+
+// WriteJSON writes the statistics as an indented JSON object.
+func (stats *GapStats) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(stats)
+}
+
+// WriteCSV writes one row per gap size: the size, its number of occurrences, the prime
+// preceding its first occurrence with that occurrence's merit and CSG ratio, and whether
+// that first occurrence was a maximal gap.
+func (stats *GapStats) WriteCSV(w io.Writer) error {
+	maximal := make(map[int]bool)
+	for _, g := range stats.Maximal {
+		maximal[g.Size] = true
+	}
+	if _, err := fmt.Fprintln(w, "gap,count,first,merit,csg,maximal"); err != nil {
+		return err
+	}
+	for i, d := range stats.Distribution {
+		f := stats.First[i]
+		_, err := fmt.Fprintf(w, "%d,%d,%d,%.6f,%.6f,%v\n", d.Size, d.Count, f.P, f.Merit, f.CSG, maximal[d.Size])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
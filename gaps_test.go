@@ -0,0 +1,127 @@
+package sieve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Maximal prime gaps below 10^6 (http://oeis.org/A002386 and http://oeis.org/A005250)
+var maximalGaps = []struct {
+	p    int // prime preceding the gap
+	size int // size of the gap
+}{
+	{2, 1}, {3, 2}, {7, 4}, {23, 6}, {89, 8}, {113, 14}, {523, 18}, {887, 20}, {1129, 22},
+	{1327, 34}, {9551, 36}, {15683, 44}, {19609, 52}, {31397, 72}, {155921, 86},
+	{360653, 96}, {370261, 112}, {492113, 114},
+}
+
+// First occurrences of even gaps (http://oeis.org/A000230)
+var firstGaps = []struct {
+	size int
+	p    int
+}{
+	{2, 3}, {4, 7}, {6, 23}, {8, 89}, {10, 139}, {12, 199}, {14, 113}, {16, 1831},
+	{18, 523}, {20, 887}, {22, 1129}, {24, 1669}, {26, 2477}, {28, 2971}, {30, 4297},
+	{32, 5591}, {34, 1327}, {36, 9551}, {38, 30593},
+}
+
+func TestGaps(t *testing.T) {
+	for _, s := range []*Sieve{New(1000000), New(1000)} {
+		stats := s.Gaps(0, 1000000)
+		if stats.Primes != 78498 {
+			t.Errorf("New(%d): %d primes; want %d", s.Size(), stats.Primes, 78498)
+		}
+		if len(stats.Maximal) != len(maximalGaps) {
+			t.Fatalf("New(%d): %d maximal gaps; want %d", s.Size(), len(stats.Maximal), len(maximalGaps))
+		}
+		for i, a := range maximalGaps {
+			if g := stats.Maximal[i]; g.P != a.p || g.Size != a.size {
+				t.Errorf("#%d, maximal gap %d after %d; want %d after %d", i, g.Size, g.P, a.size, a.p)
+			}
+		}
+		for i, a := range firstGaps {
+			found := false
+			for _, g := range stats.First {
+				if g.Size == a.size {
+					found = true
+					if g.P != a.p {
+						t.Errorf("#%d, first gap of %d after %d; want %d", i, a.size, g.P, a.p)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("#%d, no gap of %d", i, a.size)
+			}
+		}
+		if twins := stats.Count(2); twins != 8169 {
+			t.Errorf("New(%d): %d gaps of 2; want %d", s.Size(), twins, 8169)
+		}
+		if g := stats.MaxMerit; g.P != 370261 || g.Size != 112 {
+			t.Errorf("New(%d): greatest merit %v; want gap of 112 after 370261", s.Size(), g)
+		}
+	}
+}
+
+// Are gaps in a range beyond the sieve found and confined to the range?
+func TestGapsRange(t *testing.T) {
+	stats := New(2000).Gaps(1000000, 1100000)
+	if stats.Primes != 7216 {
+		t.Errorf("%d primes; want %d", stats.Primes, 7216)
+	}
+	if g := stats.Maximal[len(stats.Maximal)-1]; g.Size != 106 {
+		t.Errorf("largest gap %d; want %d", g.Size, 106)
+	}
+	total := 0
+	for _, d := range stats.Distribution {
+		total += d.Count
+	}
+	if total != stats.Primes-1 {
+		t.Errorf("%d gaps between %d primes", total, stats.Primes)
+	}
+}
+
+func TestGapsOutput(t *testing.T) {
+	stats := New(100).Gaps(0, 100)
+	var b bytes.Buffer
+	if err := stats.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if lines[0] != "gap,count,first,merit,csg,maximal" || len(lines) != 1+len(stats.Distribution) {
+		t.Errorf("CSV output:\n%s", b.String())
+	}
+	if lines[1] != "1,1,2,1.442695,2.081369,true" {
+		t.Errorf("CSV row %q", lines[1])
+	}
+
+	b.Reset()
+	if err := stats.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var decoded GapStats
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Primes != 25 || len(decoded.Maximal) != len(stats.Maximal) || decoded.MaxMerit != stats.MaxMerit {
+		t.Errorf("JSON round trip: %+v; want %+v", decoded, *stats)
+	}
+}
+
+func ExampleSieve_Gaps() {
+	// List the record gaps between primes below 1000.
+	for _, g := range New(1000).Gaps(0, 1000).Maximal {
+		fmt.Printf("%d after %d (merit %.2f)\n", g.Size, g.P, g.Merit)
+	}
+	// Output:
+	// 1 after 2 (merit 1.44)
+	// 2 after 3 (merit 1.82)
+	// 4 after 7 (merit 2.06)
+	// 6 after 23 (merit 1.91)
+	// 8 after 89 (merit 1.78)
+	// 14 after 113 (merit 2.96)
+	// 18 after 523 (merit 2.88)
+	// 20 after 887 (merit 2.95)
+}
@@ -0,0 +1,223 @@
+package sieve
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Counting Goldbach partitions for every n at once is a convolution of the prime indicator
+// with itself. It is computed exactly with number-theoretic transforms modulo three primes
+// of the form k*2^m+1 and the residues combined by the Chinese remainder theorem. Their
+// product exceeds 2^86, so any count below 2^64 is recovered exactly.
+var nttPrimes = [3]struct{ p, g uint64 }{
+	{167772161, 3},   // 5*2^25+1, primitive root 3
+	{469762049, 3},   // 7*2^26+1, primitive root 3
+	{2013265921, 31}, // 15*2^27+1, primitive root 31
+}
+
+// nttMaxLog2 is the largest transform length, 2^25, supported by all three primes.
+const nttMaxLog2 = 25
+
+// powModSmall returns a^e mod p for p < 2^32.
+func powModSmall(a, e, p uint64) uint64 {
+	result := uint64(1)
+	for a %= p; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = result * a % p
+		}
+		a = a * a % p
+	}
+	return result
+}
+
+// ntt performs an in-place number-theoretic transform of a, whose length is a power of
+// two, modulo the prime p with primitive root g. The inverse transform includes the 1/n
+// scaling.
+func ntt(a []uint32, p, g uint64, inverse bool) {
+	n := len(a)
+	shift := 64 - uint(bits.Len(uint(n))-1)
+	for i := range a { // bit-reversal permutation
+		if j := int(bits.Reverse64(uint64(i)) >> shift); i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for length := 2; length <= n; length <<= 1 {
+		w := powModSmall(g, (p-1)/uint64(length), p)
+		if inverse {
+			w = powModSmall(w, p-2, p)
+		}
+		half := length / 2
+		roots := make([]uint64, half)
+		shoup := make([]uint64, half) // floor(root*2^32/p), for division-free reduction
+		roots[0] = 1
+		for i := 1; i < half; i++ {
+			roots[i] = roots[i-1] * w % p
+		}
+		for i := range roots {
+			shoup[i] = roots[i] << 32 / p
+		}
+		for i := 0; i < n; i += length {
+			for j := 0; j < half; j++ {
+				u := uint64(a[i+j])
+				x := uint64(a[i+j+half])
+				v := x*roots[j] - (x*shoup[j]>>32)*p - p // x*root mod p, less p or not
+				v += p & uint64(int64(v)>>63)            // branch-free reductions throughout
+				sum, diff := u+v-p, u-v
+				a[i+j] = uint32(sum + p&uint64(int64(sum)>>63))
+				a[i+j+half] = uint32(diff + p&uint64(int64(diff)>>63))
+			}
+		}
+	}
+	if inverse {
+		scale := powModSmall(uint64(n), p-2, p)
+		for i := range a {
+			a[i] = uint32(uint64(a[i]) * scale % p)
+		}
+	}
+}
+
+// Constants for Garner's method: the inverse of m1 modulo m2 and of m1*m2 modulo m3.
+var (
+	crtInv1  = powModSmall(nttPrimes[0].p, nttPrimes[1].p-2, nttPrimes[1].p)
+	crtInv12 = powModSmall(nttPrimes[0].p*nttPrimes[1].p%nttPrimes[2].p, nttPrimes[2].p-2, nttPrimes[2].p)
+)
+
+// crt combines residues modulo the three transform primes by Garner's method, returning
+// the unique value below their product reduced modulo 2^64.
+func crt(r [3]uint64) uint64 {
+	m1, m2, m3 := nttPrimes[0].p, nttPrimes[1].p, nttPrimes[2].p
+	t2 := (r[1] + m2 - r[0]%m2) % m2 * crtInv1 % m2
+	t3 := (r[2] + m3 - (r[0]+m1%m3*t2)%m3) % m3 * crtInv12 % m3
+	return r[0] + m1*t2 + m1*m2*t3
+}
+
+// convolve evaluates a product of degree polynomials drawn from inputs, each of which has
+// length coefficients. The inputs are transformed, product combines the transforms
+// pointwise modulo p, and the inverse transform is taken; the first length coefficients
+// of the result are returned. The transform is long enough that nothing wraps around.
+func convolve(inputs [][]uint32, degree, length int, product func(p uint64, t []uint64) uint64) []uint64 {
+	n := 1
+	for n < degree*(length-1)+1 {
+		n <<= 1
+	}
+	var residues [3][]uint32
+	t := make([]uint64, len(inputs))
+	for k, q := range nttPrimes {
+		transforms := make([][]uint32, len(inputs))
+		for i, in := range inputs {
+			transforms[i] = make([]uint32, n)
+			copy(transforms[i], in)
+			ntt(transforms[i], q.p, q.g, false)
+		}
+		result := transforms[0]
+		for j := range result {
+			for i := range transforms {
+				t[i] = uint64(transforms[i][j])
+			}
+			result[j] = uint32(product(q.p, t))
+		}
+		ntt(result, q.p, q.g, true)
+		residues[k] = result[:length]
+	}
+
+	out := make([]uint64, length)
+	for j := range out {
+		out[j] = crt([3]uint64{uint64(residues[0][j]), uint64(residues[1][j]), uint64(residues[2][j])})
+	}
+	return out
+}
+
+// oddPrimeIndicator returns a with a[i] = 1 when 2i+1 is an odd prime, for i < length,
+// read directly from the packed table.
+func (sieve *Sieve) oddPrimeIndicator(length int) []uint32 {
+	a := make([]uint32, length)
+	for i := 1; i < length && 2*i+1 <= sieve.size; i++ {
+		a[i] = uint32(1 - sieve.bit(2*i+1))
+	}
+	return a
+}
+
+// GoldbachCounts returns r with r[n] the number of ways to write n as a sum of two primes
+// p <= q, for every even n <= limit; odd entries are zero. Goldbach's conjecture is that
+// r[n] > 0 for all even n > 2. The counts come from one self-convolution of the sieve's
+// odd-prime indicator by number-theoretic transform, so all of them together cost about
+// as much as a sort. The result is nil when limit exceeds Size() or 2^25.
+func (sieve *Sieve) GoldbachCounts(limit int) []int {
+	if limit > sieve.size || limit > 1<<nttMaxLog2 {
+		return nil
+	}
+	r := make([]int, max(limit+1, 0))
+	if limit < 4 {
+		return r
+	}
+	r[4] = 1 // 2+2, the only partition using an even prime
+
+	// odd primes 2i+1 and 2j+1 sum to n = 2k+2 for k = i+j
+	count := limit / 2
+	a := sieve.oddPrimeIndicator(count)
+	ordered := convolve([][]uint32{a}, 2, count, func(p uint64, t []uint64) uint64 {
+		return t[0] * t[0] % p
+	})
+	for k := 2; k < count; k++ {
+		pairs := int(ordered[k])
+		if k%2 == 0 {
+			pairs += int(a[k/2]) // p = q is counted once, not twice
+		}
+		r[2*k+2] = pairs / 2
+	}
+	return r
+}
+
+// WeakGoldbachCounts returns w with w[n] the number of ways to write n as a sum of three
+// primes p <= q <= r, for every odd n <= limit; even entries are zero. The weak Goldbach
+// conjecture, proven by Helfgott, is that w[n] > 0 for all odd n > 5. The ordered count is
+// the indicator convolved with itself twice, from which unordered counts follow by
+// Burnside's lemma. The result is nil when limit exceeds Size() or 2^25*2/3.
+func (sieve *Sieve) WeakGoldbachCounts(limit int) []int {
+	if limit > sieve.size || 3*limit/2 > 1<<nttMaxLog2 {
+		return nil
+	}
+	w := make([]int, max(limit+1, 0))
+
+	// odd primes 2i+1, 2j+1 and 2l+1 sum to n = 2k+3 for k = i+j+l
+	count := max((limit-3)/2+1, 0)
+	a := sieve.oddPrimeIndicator(count)
+	b := make([]uint32, count) // indicator of 2(2i+1), for partitions p+p+q
+	for i := 0; 2*i < count; i++ {
+		b[2*i] = a[i]
+	}
+	ordered := convolve([][]uint32{a}, 3, count, func(p uint64, t []uint64) uint64 {
+		return t[0] * t[0] % p * t[0] % p
+	})
+	doubled := convolve([][]uint32{a, b}, 2, count, func(p uint64, t []uint64) uint64 {
+		return t[0] * t[1] % p
+	})
+	for k := 3; k < count; k++ {
+		triples := int(ordered[k]) + 3*int(doubled[k])
+		if k%3 == 0 {
+			triples += 2 * int(a[k/3]) // p+p+p
+		}
+		w[2*k+3] = triples / 6
+	}
+	for n := 7; n <= limit; n += 2 {
+		if sieve.bit(n-4) == 0 {
+			w[n]++ // 2+2+(n-4), the only partitions using an even prime
+		}
+	}
+	return w
+}
+
+// WriteGoldbachComet writes the points of the Goldbach comet, one "n,r(n)" row for each
+// even n >= 4 of counts as returned by GoldbachCounts, after a header row.
+func WriteGoldbachComet(w io.Writer, counts []int) error {
+	if _, err := fmt.Fprintln(w, "n,partitions"); err != nil {
+		return err
+	}
+	for n := 4; n < len(counts); n += 2 {
+		if _, err := fmt.Fprintf(w, "%d,%d\n", n, counts[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package sieve
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var goldbachTests = []struct {
+	n          int
+	partitions int // ways to write n as p+q, p <= q (http://oeis.org/A045917)
+}{
+	{4, 1},
+	{6, 1},
+	{8, 1},
+	{10, 2},
+	{100, 6},
+	{1000, 28},
+	{10000, 127},
+	{100000, 810},
+	{1000000, 5402},
+}
+
+func TestGoldbachCounts(t *testing.T) {
+	s := New(1000000)
+	r := s.GoldbachCounts(1000000)
+	for i, a := range goldbachTests {
+		if r[a.n] != a.partitions {
+			t.Errorf("#%d, r(%d) = %d; want %d", i, a.n, r[a.n], a.partitions)
+		}
+	}
+}
+
+// Do the transform-based counts match direct counting with Prime?
+func TestGoldbachDirect(t *testing.T) {
+	const limit = 3001
+	s := New(limit)
+	r, w := s.GoldbachCounts(limit), s.WeakGoldbachCounts(limit)
+	if len(r) != limit+1 || len(w) != limit+1 {
+		t.Fatalf("len(r) = %d, len(w) = %d; want %d", len(r), len(w), limit+1)
+	}
+	for n := 0; n <= limit; n++ {
+		pairs, triples := 0, 0
+		for p := 2; 2*p <= n; p++ {
+			if !s.Prime(p) {
+				continue
+			}
+			if s.Prime(n - p) {
+				pairs++
+			}
+			for q := p; p+2*q <= n; q++ {
+				if s.Prime(q) && s.Prime(n-p-q) {
+					triples++
+				}
+			}
+		}
+		if n%2 == 0 && r[n] != pairs || n%2 == 1 && r[n] != 0 {
+			t.Errorf("r(%d) = %d; want %d", n, r[n], pairs)
+		}
+		if n%2 == 1 && w[n] != triples || n%2 == 0 && w[n] != 0 {
+			t.Errorf("w(%d) = %d; want %d", n, w[n], triples)
+		}
+	}
+}
+
+func TestGoldbachLimits(t *testing.T) {
+	s := New(100)
+	if r := s.GoldbachCounts(101); r != nil {
+		t.Errorf("GoldbachCounts beyond sieve = %v; want nil", r)
+	}
+	if w := s.WeakGoldbachCounts(101); w != nil {
+		t.Errorf("WeakGoldbachCounts beyond sieve = %v; want nil", w)
+	}
+	for limit := 0; limit < 10; limit++ {
+		if r, w := s.GoldbachCounts(limit), s.WeakGoldbachCounts(limit); len(r) != limit+1 || len(w) != limit+1 {
+			t.Errorf("limit %d: len(r) = %d, len(w) = %d", limit, len(r), len(w))
+		}
+	}
+}
+
+func TestGoldbachComet(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteGoldbachComet(&b, New(12).GoldbachCounts(12)); err != nil {
+		t.Fatal(err)
+	}
+	want := "n,partitions\n4,1\n6,1\n8,1\n10,2\n12,1\n"
+	if b.String() != want {
+		t.Errorf("comet is %q; want %q", b.String(), want)
+	}
+}
+
+func BenchmarkGoldbachCounts(b *testing.B) {
+	b.StopTimer()
+	s := New(1000000)
+	b.StartTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.GoldbachCounts(1000000)
+	}
+}
+
+func ExampleSieve_WeakGoldbachCounts() {
+	// Count the ways to write small odd numbers as the sum of three primes.
+	w := New(21).WeakGoldbachCounts(21)
+	for n := 7; n <= 21; n += 2 {
+		fmt.Print(w[n], " ")
+	}
+	fmt.Println()
+	// Output:
+	// 1 2 2 2 3 4 3 5
+}
+
+func ExampleWriteGoldbachComet() {
+	var b strings.Builder
+	WriteGoldbachComet(&b, New(20).GoldbachCounts(20))
+	fmt.Print(b.String())
+	// Output:
+	// n,partitions
+	// 4,1
+	// 6,1
+	// 8,1
+	// 10,2
+	// 12,1
+	// 14,2
+	// 16,2
+	// 18,2
+	// 20,2
+}
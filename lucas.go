@@ -0,0 +1,379 @@
+package sieve
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// The Baillie-PSW test (Baillie and Wagstaff, 1980; Pomerance, Selfridge and Wagstaff,
+// 1980) combines a strong probable-prime test to base 2 with a strong Lucas test. The
+// pseudoprimes of the two tests seem to avoid one another, and no composite has been
+// found to pass both; Feitsma and Galway showed that none exists below 2^64.
+
+// LucasSequence returns U_k and V_k modulo the odd m for the Lucas sequences with
+// parameters P and Q, defined by U_0 = 0, U_1 = 1, V_0 = 2, V_1 = P and
+// X_{j+1} = P·X_j - Q·X_{j-1}; P=1, Q=-1 gives the Fibonacci and Lucas numbers. It also
+// returns Q^k mod m. The doubling formulas U_2j = U_j·V_j and V_2j = V_j² - 2Q^j take
+// each bit of k, with U_{j+1} = (P·U_j + V_j)/2 and V_{j+1} = (D·U_j + P·V_j)/2, where
+// D = P² - 4Q, for each one bit. It returns zeros if m is not odd and positive.
+func LucasSequence(p, q int64, k, m *big.Int) (u, v, qk *big.Int) {
+	u, v, qk = new(big.Int), new(big.Int), new(big.Int)
+	if m.Sign() <= 0 || m.Bit(0) == 0 || k.Sign() < 0 {
+		return
+	}
+	P, Q := big.NewInt(p), big.NewInt(q)
+	D := new(big.Int).Sub(new(big.Int).Mul(P, P), new(big.Int).Lsh(Q, 2))
+	if k.Sign() == 0 {
+		v.SetInt64(2)
+		v.Mod(v, m)
+		qk.Mod(big.NewInt(1), m)
+		return
+	}
+	half := func(x *big.Int) { // x/2 mod m, for x in [0, 2m)
+		if x.Bit(0) == 1 {
+			x.Add(x, m)
+		}
+		x.Rsh(x, 1)
+	}
+	t := new(big.Int)
+	u.SetInt64(1)
+	v.Mod(P, m)
+	qk.Mod(Q, m)
+	for i := k.BitLen() - 2; i >= 0; i-- {
+		u.Mul(u, v).Mod(u, m)
+		v.Mul(v, v).Sub(v, t.Lsh(qk, 1)).Mod(v, m)
+		qk.Mul(qk, qk).Mod(qk, m)
+		if k.Bit(i) == 1 {
+			t.Mul(D, u)
+			u.Mul(u, P).Add(u, v).Mod(u, m)
+			v.Mul(v, P).Add(v, t).Mod(v, m)
+			half(u)
+			half(v)
+			qk.Mul(qk, Q).Mod(qk, m)
+		}
+	}
+	return
+}
+
+// LucasSequence64 is LucasSequence for a uint64 modulus.
+func LucasSequence64(p, q int64, k, m uint64) (u, v, qk uint64) {
+	if m&1 == 0 {
+		return 0, 0, 0
+	}
+	P, Q := reduce64(p, m), reduce64(q, m)
+	D := modarith.SubMod(modarith.MulMod(P, P, m), modarith.MulMod(4%m, Q, m), m)
+	if k == 0 {
+		return 0, 2 % m, 1 % m
+	}
+	u, v, qk = 1%m, P, Q
+	for i := bits.Len64(k) - 2; i >= 0; i-- {
+		u = modarith.MulMod(u, v, m)
+		v = modarith.SubMod(modarith.MulMod(v, v, m), modarith.AddMod(qk, qk, m), m)
+		qk = modarith.MulMod(qk, qk, m)
+		if k>>uint(i)&1 == 1 {
+			u, v = modarith.AddMod(modarith.MulMod(u, P, m), v, m), modarith.AddMod(modarith.MulMod(v, P, m), modarith.MulMod(u, D, m), m)
+			u, v = halfMod64(u, m), halfMod64(v, m)
+			qk = modarith.MulMod(qk, Q, m)
+		}
+	}
+	return u, v, qk
+}
+
+// reduce64 returns a mod m in [0, m).
+func reduce64(a int64, m uint64) uint64 {
+	if a < 0 {
+		return (m - magnitude(a)%m) % m
+	}
+	return uint64(a) % m
+}
+
+// halfMod64 returns a/2 mod the odd m for a < m.
+func halfMod64(a, m uint64) uint64 {
+	if a&1 == 0 {
+		return a >> 1
+	}
+	return a>>1 + m>>1 + 1 // (a+m)/2 with both odd
+}
+
+// selfridge returns the first D in 5, -7, 9, -11, ... with Jacobi symbol (D/n) = -1,
+// reporting false if n has a factor revealed along the way or is a perfect square, for
+// which there is no such D. The odd n must exceed 2.
+func selfridge(n uint64) (int64, bool) {
+	for d := int64(5); ; {
+		switch jacobi(reduce64(d, n), n) {
+		case -1:
+			return d, true
+		case 0:
+			if magnitude(d) != n {
+				return 0, false // shares a factor with n
+			}
+		}
+		if r := isqrt64(n); d == 13 && r*r == n {
+			return 0, false
+		}
+		if d > 0 {
+			d = -d - 2
+		} else {
+			d = -d + 2
+		}
+	}
+}
+
+// StrongLucasProbablePrime64 reports whether the odd n > 2 passes the strong Lucas
+// test with Selfridge's parameters: the first D of 5, -7, 9, -11, ... with (D/n) = -1,
+// P = 1 and Q = (1-D)/4. Writing n+1 = d·2^s with d odd, n passes when U_d ≡ 0 or
+// V_{d·2^r} ≡ 0 (mod n) for some 0 <= r < s.
+func StrongLucasProbablePrime64(n uint64) bool {
+	if n < 3 || n&1 == 0 || n == 1<<64-1 {
+		return n == 2
+	}
+	d, ok := selfridge(n)
+	if !ok {
+		return false
+	}
+	k := n + 1
+	s := bits.TrailingZeros64(k)
+	k >>= uint(s)
+	u, v, qk := LucasSequence64(1, (1-d)/4, k, n)
+	if u == 0 || v == 0 {
+		return true
+	}
+	for r := 1; r < s; r++ {
+		v = modarith.SubMod(modarith.MulMod(v, v, n), modarith.AddMod(qk, qk, n), n)
+		if v == 0 {
+			return true
+		}
+		qk = modarith.MulMod(qk, qk, n)
+	}
+	return false
+}
+
+// StrongLucasProbablePrime is StrongLucasProbablePrime64 for a big.Int.
+func StrongLucasProbablePrime(n *big.Int) bool {
+	if n.Cmp(big.NewInt(3)) < 0 || n.Bit(0) == 0 {
+		return n.Cmp(big.NewInt(2)) == 0
+	}
+	if n.IsUint64() {
+		return StrongLucasProbablePrime64(n.Uint64())
+	}
+	d, ok := selfridgeBig(n)
+	if !ok {
+		return false
+	}
+	k := new(big.Int).Add(n, big.NewInt(1))
+	s := k.TrailingZeroBits()
+	k.Rsh(k, s)
+	u, v, qk := LucasSequence(1, (1-d)/4, k, n)
+	if u.Sign() == 0 || v.Sign() == 0 {
+		return true
+	}
+	t := new(big.Int)
+	for r := uint(1); r < s; r++ {
+		v.Mul(v, v).Sub(v, t.Lsh(qk, 1)).Mod(v, n)
+		if v.Sign() == 0 {
+			return true
+		}
+		qk.Mul(qk, qk).Mod(qk, n)
+	}
+	return false
+}
+
+// selfridgeBig is selfridge for a big.Int beyond 64 bits.
+func selfridgeBig(n *big.Int) (int64, bool) {
+	t := new(big.Int)
+	for d := int64(5); ; {
+		switch big.Jacobi(t.SetInt64(d), n) {
+		case -1:
+			return d, true
+		case 0:
+			return 0, false // n is too large to equal |d|
+		}
+		if d == 13 && t.Sqrt(n).Mul(t, t).Cmp(n) == 0 {
+			return 0, false
+		}
+		if d > 0 {
+			d = -d - 2
+		} else {
+			d = -d + 2
+		}
+	}
+}
+
+// ExtraStrongLucasProbablePrime64 reports whether the odd n > 2 passes Baillie's
+// extra strong Lucas test (Grantham, 2001): with the least P >= 3 for which
+// D = P²-4 has (D/n) = -1, Q = 1, and n+1 = d·2^s with d odd, n passes when U_d ≡ 0
+// and V_d ≡ ±2, or V_{d·2^r} ≡ 0 for some 0 <= r < s-1. Its pseudoprimes are a subset
+// of those of the strong test with the same parameters, and it costs less.
+func ExtraStrongLucasProbablePrime64(n uint64) bool {
+	if n < 3 || n&1 == 0 || n == 1<<64-1 {
+		return n == 2
+	}
+	p := uint64(3)
+	for ; ; p++ {
+		d := p*p - 4
+		j := jacobi(d%n, n)
+		if j == -1 {
+			break
+		}
+		if j == 0 && d%n != 0 {
+			return false // shares a factor with n
+		}
+		if p == 40 {
+			if r := isqrt64(n); r*r == n {
+				return false
+			}
+		}
+	}
+	k := n + 1
+	s := bits.TrailingZeros64(k)
+	k >>= uint(s)
+	u, v, _ := LucasSequence64(int64(p), 1, k, n)
+	if u == 0 && (v == 2 || v == n-2) {
+		return true
+	}
+	for r := 0; r < s-1; r++ {
+		if v == 0 {
+			return true
+		}
+		v = modarith.SubMod(modarith.MulMod(v, v, n), 2, n)
+	}
+	return false
+}
+
+// ExtraStrongLucasProbablePrime is ExtraStrongLucasProbablePrime64 for a big.Int.
+func ExtraStrongLucasProbablePrime(n *big.Int) bool {
+	if n.Cmp(big.NewInt(3)) < 0 || n.Bit(0) == 0 {
+		return n.Cmp(big.NewInt(2)) == 0
+	}
+	if n.IsUint64() {
+		return ExtraStrongLucasProbablePrime64(n.Uint64())
+	}
+	t := new(big.Int)
+	p := int64(3)
+	for ; ; p++ {
+		j := big.Jacobi(t.SetInt64(p*p-4), n)
+		if j == -1 {
+			break
+		}
+		if j == 0 {
+			return false // n is too large to divide p²-4
+		}
+		if p == 40 && t.Sqrt(n).Mul(t, t).Cmp(n) == 0 {
+			return false
+		}
+	}
+	k := new(big.Int).Add(n, big.NewInt(1))
+	s := k.TrailingZeroBits()
+	k.Rsh(k, s)
+	u, v, _ := LucasSequence(p, 1, k, n)
+	two := big.NewInt(2)
+	if u.Sign() == 0 && (v.Cmp(two) == 0 || t.Sub(n, v).Cmp(two) == 0) {
+		return true
+	}
+	for r := uint(0); r+1 < s; r++ {
+		if v.Sign() == 0 {
+			return true
+		}
+		v.Mul(v, v).Sub(v, two).Mod(v, n)
+	}
+	return false
+}
+
+// BPSW64 is the Baillie-PSW test for a uint64: trial division by small primes, a strong
+// probable-prime test to base 2, and a strong Lucas test. It is exact for every uint64
+// and serves to cross-check the deterministic Miller-Rabin test.
+func BPSW64(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for _, p := range witnesses64 {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+	return n < 41*41 || strongProbable64(n, 2) && StrongLucasProbablePrime64(n)
+}
+
+// BPSW is the Baillie-PSW probable-prime test for a big.Int.
+func BPSW(n *big.Int) bool {
+	if n.Sign() <= 0 {
+		return false
+	}
+	if n.IsUint64() {
+		return BPSW64(n.Uint64())
+	}
+	var r big.Int
+	for _, p := range witnesses64 {
+		if r.Mod(n, r.SetUint64(p)).Sign() == 0 {
+			return false // n is too large to equal p
+		}
+	}
+	return strongProbable(n, big.NewInt(2)) && StrongLucasProbablePrime(n)
+}
+
+// strongProbable is strongProbable64 for a big.Int.
+func strongProbable(n, a *big.Int) bool {
+	one := big.NewInt(1)
+	m := new(big.Int).Sub(n, one) // n-1
+	s := m.TrailingZeroBits()
+	d := new(big.Int).Rsh(m, s)
+	x := new(big.Int).Exp(a, d, n)
+	if x.Cmp(one) == 0 || x.Cmp(m) == 0 {
+		return true
+	}
+	for r := uint(1); r < s; r++ {
+		x.Mul(x, x).Mod(x, n)
+		if x.Cmp(m) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// trialReach bounds the sieve primes used for trial division before a probable-prime
+// test. Beyond it, a Lucas test costs less than the divisions it would save.
+const trialReach = 1 << 10
+
+// bpsw tests n, which is larger than the sieve's primes, by trial division with them up
+// to trialReach and then by the Baillie-PSW test.
+func (sieve *Sieve) bpsw(n uint64) bool {
+	for p := 3; p <= min(sieve.size, trialReach); p += 2 {
+		if sieve.bit(p) == 0 && n%uint64(p) == 0 {
+			return false
+		}
+	}
+	return BPSW64(n)
+}
+
+// bpswBig is bpsw for a big.Int beyond 64 bits. The primes are gathered into products
+// that fit in a word so that each big division removes several at once.
+func (sieve *Sieve) bpswBig(n *big.Int) bool {
+	var r, m big.Int
+	product, primes := uint64(1), []uint64{}
+	divides := func() bool {
+		rest := r.Mod(n, m.SetUint64(product)).Uint64()
+		for _, p := range primes {
+			if rest%p == 0 {
+				return true
+			}
+		}
+		product, primes = 1, primes[:0]
+		return false
+	}
+	for p := 3; p <= min(sieve.size, trialReach); p += 2 {
+		if sieve.bit(p) != 0 {
+			continue
+		}
+		if hi, _ := bits.Mul64(product, uint64(p)); hi != 0 && divides() {
+			return false
+		}
+		product *= uint64(p)
+		primes = append(primes, uint64(p))
+	}
+	if len(primes) > 0 && divides() {
+		return false
+	}
+	return BPSW(n)
+}
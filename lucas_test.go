@@ -0,0 +1,141 @@
+package sieve
+
+import (
+	"math/big"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+var lucasPseudoprimes = []struct {
+	name  string
+	test  func(uint64) bool
+	count int   // below 10^6
+	first []int // http://oeis.org/A217255 and http://oeis.org/A217719
+}{
+	{"strong", StrongLucasProbablePrime64, 58, []int{5459, 5777, 10877, 16109, 18971, 22499, 24569, 25199, 40309, 58519, 75077, 97439}},
+	{"extra strong", ExtraStrongLucasProbablePrime64, 42, []int{989, 3239, 5777, 10877, 27971, 29681, 30739, 31631, 39059, 72389, 73919, 75077}},
+}
+
+func TestLucasPseudoprimes(t *testing.T) {
+	sieve := New(1000000)
+	for i, a := range lucasPseudoprimes {
+		var pseudo []int
+		for n := 3; n < 1000000; n += 2 {
+			switch prime, passes := sieve.Prime(n), a.test(uint64(n)); {
+			case prime && !passes:
+				t.Errorf("#%d, %s Lucas test rejects prime %d", i, a.name, n)
+			case passes && !prime:
+				pseudo = append(pseudo, n)
+			}
+		}
+		if len(pseudo) != a.count || !slices.Equal(pseudo[:len(a.first)], a.first) {
+			t.Errorf("#%d, %s Lucas pseudoprimes %v...; want %d beginning %v", i, a.name, pseudo[:min(len(pseudo), 12)], a.count, a.first)
+		}
+	}
+}
+
+func TestLucasBig(t *testing.T) {
+	sieve := New(1000)
+	for n := uint64(3); n < 200000; n += 2 {
+		b := new(big.Int).SetUint64(n)
+		if StrongLucasProbablePrime(b) != StrongLucasProbablePrime64(n) || ExtraStrongLucasProbablePrime(b) != ExtraStrongLucasProbablePrime64(n) {
+			t.Errorf("big and uint64 Lucas tests disagree on %d", n)
+		}
+		if BPSW(b) != sieve.isPrime(n) {
+			t.Errorf("BPSW(%d) = %v", n, BPSW(b))
+		}
+	}
+	for i, s := range []string{
+		"170141183460469231731687303715884105727", // 2^127-1
+		"618970019642690137449562111",             // 2^89-1
+		"3317044064679887385961981",               // a strong pseudoprime to bases 2 through 37
+		"318665857834031151167461",                // another
+		"57896044618658097711785492504343953926634992332820282019728792003956564819949", // 2^255-19
+	} {
+		n, _ := new(big.Int).SetString(s, 10)
+		want := n.ProbablyPrime(20)
+		if BPSW(n) != want || StrongLucasProbablePrime(n) != want || ExtraStrongLucasProbablePrime(n) != want {
+			t.Errorf("#%d, BPSW(%v) = %v, strong %v, extra %v; want %v", i, n, BPSW(n), StrongLucasProbablePrime(n), ExtraStrongLucasProbablePrime(n), want)
+		}
+	}
+}
+
+func TestBPSW64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := range 100000 {
+		n := r.Uint64() | 1
+		if i&1 == 0 {
+			n >>= uint(r.Intn(60))
+		}
+		if BPSW64(n) != isPrime64(n) {
+			t.Errorf("BPSW64(%d) = %v; Miller-Rabin says %v", n, BPSW64(n), isPrime64(n))
+		}
+	}
+	for i, n := range []uint64{2047, 3215031751, 3825123056546413051, maxPrime64, 1<<64 - 1, 1<<61 - 1} {
+		if BPSW64(n) != isPrime64(n) {
+			t.Errorf("#%d, BPSW64(%d) = %v", i, n, BPSW64(n))
+		}
+	}
+}
+
+func TestLucasSequence(t *testing.T) {
+	// Fibonacci and Lucas numbers
+	fib, luc := []uint64{0, 1}, []uint64{2, 1}
+	for len(fib) < 90 {
+		fib = append(fib, fib[len(fib)-1]+fib[len(fib)-2])
+		luc = append(luc, luc[len(luc)-1]+luc[len(luc)-2])
+	}
+	m := uint64(1<<63 + 1)
+	for k := range uint64(90) {
+		u, v, _ := LucasSequence64(1, -1, k, m)
+		if u != fib[k]%m || v != luc[k]%m {
+			t.Errorf("LucasSequence64(1, -1, %d) = %d, %d; want %d, %d", k, u, v, fib[k]%m, luc[k]%m)
+		}
+		bu, bv, _ := LucasSequence(1, -1, new(big.Int).SetUint64(k), new(big.Int).SetUint64(m))
+		if bu.Uint64() != u || bv.Uint64() != v {
+			t.Errorf("LucasSequence(1, -1, %d) = %v, %v; want %d, %d", k, bu, bv, u, v)
+		}
+	}
+	u, v, qk := LucasSequence64(3, -2, 1000000000000, 1000000007)
+	if u != 410334105 || v != 7625821 || qk != 959366170 {
+		t.Errorf("LucasSequence64(3, -2, 10^12, 10^9+7) = %d, %d, %d; want 410334105, 7625821, 959366170", u, v, qk)
+	}
+}
+
+func BenchmarkBPSW64(b *testing.B) {
+	for b.Loop() {
+		BPSW64(maxPrime64)
+	}
+}
+
+func TestPrimeBeyond(t *testing.T) {
+	sieve := New(100)
+	for i, a := range []struct {
+		n     int
+		prime bool
+	}{
+		{1000000007, true},
+		{1000000007 * 3, false},
+		{2147483647 * 2147483629, false},
+		{1<<61 - 1, true},
+		{9223372036854775783, true}, // largest prime below 2^63
+	} {
+		if p := sieve.Prime(a.n); p != a.prime {
+			t.Errorf("#%d, New(100).Prime(%d) = %v; want %v", i, a.n, p, a.prime)
+		}
+	}
+	m127, _ := new(big.Int).SetString("170141183460469231731687303715884105727", 10)
+	for i, a := range []struct {
+		n     *big.Int
+		prime bool
+	}{
+		{m127, true},
+		{new(big.Int).Mul(m127, big.NewInt(997)), false},
+		{new(big.Int).Mul(m127, big.NewInt(1009)), false},
+	} {
+		if p := New(1 << 12).probablyPrime(a.n); p != a.prime {
+			t.Errorf("#%d, probablyPrime(%v) = %v; want %v", i, a.n, p, a.prime)
+		}
+	}
+}
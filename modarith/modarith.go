@@ -0,0 +1,222 @@
+// Package modarith provides modular arithmetic on uint64: products and powers through a
+// 128-bit intermediate, Montgomery multiplication for long chains of products with one
+// modulus, the extended Euclidean algorithm and modular inverses, and the Chinese
+// remainder theorem for moduli that need not be coprime.
+package modarith
+
+import "math/bits"
+
+// MulMod returns a·b mod m for a, b < m, dividing the 128-bit product by m.
+func MulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, r := bits.Div64(hi, lo, m)
+	return r
+}
+
+// AddMod returns a+b mod m for a, b < m, without overflow.
+func AddMod(a, b, m uint64) uint64 {
+	s, carry := bits.Add64(a, b, 0)
+	if carry != 0 || s >= m {
+		s -= m
+	}
+	return s
+}
+
+// SubMod returns a-b mod m for a, b < m.
+func SubMod(a, b, m uint64) uint64 {
+	if a >= b {
+		return a - b
+	}
+	return m - b + a
+}
+
+// PowMod returns a^e mod m, for m > 0, by left-to-right binary exponentiation.
+func PowMod(a, e, m uint64) uint64 {
+	result := uint64(1) % m
+	a %= m
+	for i := bits.Len64(e) - 1; i >= 0; i-- {
+		result = MulMod(result, result, m)
+		if e>>uint(i)&1 == 1 {
+			result = MulMod(result, a, m)
+		}
+	}
+	return result
+}
+
+// GCD returns gcd(a, b) by Stein's binary algorithm, which needs no division.
+func GCD(a, b uint64) uint64 {
+	if a == 0 || b == 0 {
+		return a | b
+	}
+	shift := bits.TrailingZeros64(a | b)
+	a >>= uint(bits.TrailingZeros64(a))
+	for b != 0 {
+		b >>= uint(bits.TrailingZeros64(b))
+		if a > b {
+			a, b = b, a
+		}
+		b -= a
+	}
+	return a << uint(shift)
+}
+
+// ExtGCD returns g = gcd(a, b) and Bézout coefficients with a·x + b·y = g. They are the
+// ones Euclid's algorithm yields, with |x| <= b/2g and |y| <= a/2g when a, b > 0, so they
+// fit in an int64 although the intermediate values may not; those wrap harmlessly, as the
+// arithmetic is then exact mod 2^64.
+func ExtGCD(a, b uint64) (g uint64, x, y int64) {
+	x0, x1, y0, y1 := int64(1), int64(0), int64(0), int64(1)
+	for b != 0 {
+		q := a / b
+		a, b = b, a-q*b
+		x0, x1 = x1, x0-int64(q)*x1
+		y0, y1 = y1, y0-int64(q)*y1
+	}
+	return a, x0, y0
+}
+
+// InvMod returns the inverse of a mod m, reporting false when a and m are not coprime.
+func InvMod(a, m uint64) (uint64, bool) {
+	if m == 0 {
+		return 0, false
+	}
+	g, x, _ := ExtGCD(a%m, m)
+	if g != 1 {
+		return 0, false
+	}
+	if x < 0 {
+		return m - uint64(-x), true
+	}
+	return uint64(x) % m, true
+}
+
+// LinearCongruence solves a·x ≡ b (mod m). With g = gcd(a, m), there are solutions when
+// g divides b, and they are x ≡ (b/g)·(a/g)⁻¹ (mod m/g). It returns the least, and the
+// step m/g between them, or false if there are none. The modulus m must be positive.
+func LinearCongruence(a, b, m uint64) (x, step uint64, ok bool) {
+	a, b = a%m, b%m
+	g, _, _ := ExtGCD(a, m)
+	if b%g != 0 {
+		return 0, 0, false
+	}
+	step = m / g
+	inv, _ := InvMod(a/g, step)
+	return MulMod(b/g%step, inv, step), step, true
+}
+
+// CRT returns the x, least among those below the least common multiple m of the moduli,
+// with x ≡ residues[i] (mod moduli[i]) for each i. The moduli must be positive but need
+// not be coprime: the congruences are merged one at a time, x ≡ r (mod M) with
+// x ≡ s (mod n) by solving M·t ≡ s-r (mod n), which needs gcd(M, n) to divide s-r. CRT
+// reports false when that fails, so there is no solution, when m overflows a uint64, or
+// when the slices differ in length. No congruences give x = 0, m = 1.
+func CRT(residues, moduli []uint64) (x, m uint64, ok bool) {
+	if len(residues) != len(moduli) {
+		return 0, 0, false
+	}
+	x, m = 0, 1
+	for i, n := range moduli {
+		if n == 0 {
+			return 0, 0, false
+		}
+		s := residues[i] % n
+		t, step, ok := LinearCongruence(m, SubMod(s, x%n, n), n)
+		if !ok {
+			return 0, 0, false
+		}
+		hi, lcm := bits.Mul64(m, step)
+		if hi != 0 {
+			return 0, 0, false
+		}
+		// x + m·t < m + m·(step-1) = lcm
+		x += m * t
+		m = lcm
+	}
+	return x, m, true
+}
+
+// Montgomery multiplies modulo an odd m without division (Montgomery, 1985). Values are
+// kept in Montgomery form, a·R mod m with R = 2^64, in which the product of x and y is
+// x·y/R mod m: the low word of x·y is cancelled by subtracting u·m, u = x·y·m⁻¹ mod R,
+// whose low word equals that of x·y, leaving a multiple of R to shift away.
+type Montgomery struct {
+	m   uint64
+	inv uint64 // m⁻¹ mod 2^64
+	one uint64 // R mod m
+	r2  uint64 // R² mod m
+}
+
+// NewMontgomery returns a Montgomery context for the odd modulus m > 1, or nil for others.
+func NewMontgomery(m uint64) *Montgomery {
+	if m&1 == 0 || m < 3 {
+		return nil
+	}
+	inv := m // correct to 3 bits, as m·m ≡ 1 (mod 8); Newton's step doubles that
+	for range 5 {
+		inv *= 2 - m*inv
+	}
+	one := -m % m
+	return &Montgomery{m: m, inv: inv, one: one, r2: MulMod(one, one, m)}
+}
+
+// Modulus returns m.
+func (mt *Montgomery) Modulus() uint64 {
+	return mt.m
+}
+
+// One returns 1 in Montgomery form.
+func (mt *Montgomery) One() uint64 {
+	return mt.one
+}
+
+// redc returns (hi·2^64 + lo)/R mod m for hi < m.
+func (mt *Montgomery) redc(hi, lo uint64) uint64 {
+	u := lo * mt.inv
+	h, _ := bits.Mul64(u, mt.m) // u·m has the same low word as the input
+	if hi < h {
+		return hi - h + mt.m
+	}
+	return hi - h
+}
+
+// To returns a in Montgomery form.
+func (mt *Montgomery) To(a uint64) uint64 {
+	return mt.Mul(a%mt.m, mt.r2)
+}
+
+// From returns the value of x in Montgomery form.
+func (mt *Montgomery) From(x uint64) uint64 {
+	return mt.redc(0, x)
+}
+
+// Mul returns the product of x and y in Montgomery form.
+func (mt *Montgomery) Mul(x, y uint64) uint64 {
+	return mt.redc(bits.Mul64(x, y))
+}
+
+// Add returns the sum of x and y in Montgomery form.
+func (mt *Montgomery) Add(x, y uint64) uint64 {
+	return AddMod(x, y, mt.m)
+}
+
+// Sub returns the difference of x and y in Montgomery form.
+func (mt *Montgomery) Sub(x, y uint64) uint64 {
+	return SubMod(x, y, mt.m)
+}
+
+// Pow returns x^e for x in Montgomery form.
+func (mt *Montgomery) Pow(x, e uint64) uint64 {
+	result := mt.one
+	for i := bits.Len64(e) - 1; i >= 0; i-- {
+		result = mt.Mul(result, result)
+		if e>>uint(i)&1 == 1 {
+			result = mt.Mul(result, x)
+		}
+	}
+	return result
+}
+
+// PowMod returns a^e mod m, as the package function does, by way of Montgomery form.
+func (mt *Montgomery) PowMod(a, e uint64) uint64 {
+	return mt.From(mt.Pow(mt.To(a), e))
+}
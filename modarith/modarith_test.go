@@ -0,0 +1,189 @@
+package modarith
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// moduli spans the sizes where the arithmetic differs: tiny, 32-bit, just below 2^63,
+// and just below 2^64, where sums overflow.
+var moduli = []uint64{1, 2, 3, 7, 1000003, 1<<32 - 5, 1<<63 - 25, 1<<64 - 59, 1<<64 - 1, 1<<64 - 2}
+
+func bigMod(f func(a, b, m *big.Int) *big.Int, a, b, m uint64) uint64 {
+	A, B, M := new(big.Int).SetUint64(a), new(big.Int).SetUint64(b), new(big.Int).SetUint64(m)
+	return f(A, B, M).Mod(f(A, B, M), M).Uint64()
+}
+
+func TestArithmetic(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	mul := func(a, b, m *big.Int) *big.Int { return new(big.Int).Mul(a, b) }
+	add := func(a, b, m *big.Int) *big.Int { return new(big.Int).Add(a, b) }
+	sub := func(a, b, m *big.Int) *big.Int { return new(big.Int).Sub(a, b) }
+	pow := func(a, b, m *big.Int) *big.Int { return new(big.Int).Exp(a, b, m) }
+	for _, m := range moduli {
+		mt := NewMontgomery(m)
+		for range 1000 {
+			a, b := r.Uint64()%m, r.Uint64()%m
+			if m > 2 && r.Intn(10) == 0 {
+				a = m - 1
+			}
+			e := r.Uint64() >> uint(r.Intn(64))
+			if have, want := MulMod(a, b, m), bigMod(mul, a, b, m); have != want {
+				t.Errorf("MulMod(%d, %d, %d) = %d; want %d", a, b, m, have, want)
+			}
+			if have, want := AddMod(a, b, m), bigMod(add, a, b, m); have != want {
+				t.Errorf("AddMod(%d, %d, %d) = %d; want %d", a, b, m, have, want)
+			}
+			if have, want := SubMod(a, b, m), bigMod(sub, a, b, m); have != want {
+				t.Errorf("SubMod(%d, %d, %d) = %d; want %d", a, b, m, have, want)
+			}
+			if have, want := PowMod(a, e, m), bigMod(pow, a, e, m); have != want {
+				t.Errorf("PowMod(%d, %d, %d) = %d; want %d", a, e, m, have, want)
+			}
+			if mt == nil {
+				continue
+			}
+			x, y := mt.To(a), mt.To(b)
+			if mt.From(x) != a || mt.From(mt.Mul(x, y)) != MulMod(a, b, m) ||
+				mt.From(mt.Add(x, y)) != AddMod(a, b, m) || mt.From(mt.Sub(x, y)) != SubMod(a, b, m) {
+				t.Errorf("Montgomery(%d) arithmetic on %d, %d", m, a, b)
+			}
+			if have, want := mt.PowMod(a, e), PowMod(a, e, m); have != want {
+				t.Errorf("Montgomery(%d).PowMod(%d, %d) = %d; want %d", m, a, e, have, want)
+			}
+		}
+		if (mt == nil) != (m%2 == 0 || m == 1) {
+			t.Errorf("NewMontgomery(%d) = %v", m, mt)
+		} else if mt != nil && (mt.Modulus() != m || mt.From(mt.One()) != 1) {
+			t.Errorf("Montgomery(%d) has modulus %d, one %d", m, mt.Modulus(), mt.From(mt.One()))
+		}
+	}
+}
+
+func TestExtGCD(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	pairs := [][2]uint64{{0, 0}, {0, 5}, {5, 0}, {1, 1}, {12, 18}, {1<<64 - 1, 1<<64 - 2}, {1<<64 - 1, 1}, {1, 1<<64 - 1}}
+	for range 1000 {
+		pairs = append(pairs, [2]uint64{r.Uint64() >> uint(r.Intn(64)), r.Uint64() >> uint(r.Intn(64))})
+	}
+	var lhs, t1, g big.Int
+	for _, p := range pairs {
+		a, b := p[0], p[1]
+		have, x, y := ExtGCD(a, b)
+		A, B := new(big.Int).SetUint64(a), new(big.Int).SetUint64(b)
+		g.GCD(nil, nil, A, B)
+		lhs.Mul(A, big.NewInt(x)).Add(&lhs, t1.Mul(B, big.NewInt(y)))
+		if have != g.Uint64() || lhs.Cmp(&g) != 0 || GCD(a, b) != have {
+			t.Errorf("ExtGCD(%d, %d) = %d, %d, %d", a, b, have, x, y)
+		}
+		if inv, ok := InvMod(a, b); ok != (b > 0 && have == 1) || ok && (inv >= b || MulMod(inv, a%b, b) != 1%b) {
+			t.Errorf("InvMod(%d, %d) = %d, %v", a, b, inv, ok)
+		}
+	}
+}
+
+func TestLinearCongruence(t *testing.T) {
+	for i, a := range []struct {
+		a, b, m, x, step uint64
+		ok               bool
+	}{
+		{3, 1, 7, 5, 7, true},
+		{6, 4, 10, 4, 5, true},
+		{6, 3, 10, 0, 0, false},
+		{0, 0, 9, 0, 1, true},
+		{0, 1, 9, 0, 0, false},
+		{1<<64 - 1, 1, 1<<64 - 2, 1, 1<<64 - 2, true},
+	} {
+		x, step, ok := LinearCongruence(a.a, a.b, a.m)
+		if x != a.x || step != a.step || ok != a.ok {
+			t.Errorf("#%d, LinearCongruence(%d, %d, %d) = %d, %d, %v; want %d, %d, %v", i, a.a, a.b, a.m, x, step, ok, a.x, a.step, a.ok)
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	for i, a := range []struct {
+		residues, moduli []uint64
+		x, m             uint64
+		ok               bool
+	}{
+		{nil, nil, 0, 1, true},
+		{[]uint64{2, 3, 2}, []uint64{3, 5, 7}, 23, 105, true},
+		{[]uint64{3, 5}, []uint64{4, 6}, 11, 12, true},
+		{[]uint64{3, 4}, []uint64{4, 6}, 0, 0, false},
+		{[]uint64{10, 4}, []uint64{12, 6}, 10, 12, true},
+		{[]uint64{1}, []uint64{0}, 0, 0, false},
+		{[]uint64{1, 2}, []uint64{1 << 32, 1<<32 + 1}, 0, 0, false},
+		{[]uint64{1, 2}, []uint64{1<<32 - 1, 1<<32 + 1}, 9223372034707292161, 1<<64 - 1, true},
+		{[]uint64{1, 2}, []uint64{1 << 32, 1<<33 + 1}, 0, 0, false},
+		{[]uint64{1}, []uint64{1, 2}, 0, 0, false},
+	} {
+		x, m, ok := CRT(a.residues, a.moduli)
+		if x != a.x || m != a.m || ok != a.ok {
+			t.Errorf("#%d, CRT(%v, %v) = %d, %d, %v; want %d, %d, %v", i, a.residues, a.moduli, x, m, ok, a.x, a.m, a.ok)
+		}
+	}
+
+	r := rand.New(rand.NewSource(3))
+	for range 1000 {
+		var residues, mods []uint64
+		x := r.Uint64()
+		for range 1 + r.Intn(5) {
+			n := uint64(1 + r.Intn(1000))
+			residues, mods = append(residues, x%n), append(mods, n)
+		}
+		have, m, ok := CRT(residues, mods)
+		if !ok || have >= m || have%m != x%m {
+			t.Errorf("CRT(%v, %v) = %d, %d, %v; want %d", residues, mods, have, m, ok, x%m)
+		}
+	}
+}
+
+var sink uint64
+
+func BenchmarkMulMod(b *testing.B) {
+	m := uint64(1<<64 - 59)
+	x := uint64(3)
+	for b.Loop() {
+		x = MulMod(x, x, m)
+	}
+	sink = x
+}
+
+func BenchmarkMontgomeryMul(b *testing.B) {
+	mt := NewMontgomery(1<<64 - 59)
+	x := mt.To(3)
+	for b.Loop() {
+		x = mt.Mul(x, x)
+	}
+	sink = x
+}
+
+func BenchmarkPowMod(b *testing.B) {
+	m := uint64(1<<64 - 59)
+	for b.Loop() {
+		sink = PowMod(3, m-1, m)
+	}
+}
+
+func BenchmarkMontgomeryPowMod(b *testing.B) {
+	m := uint64(1<<64 - 59)
+	for b.Loop() {
+		sink = NewMontgomery(m).PowMod(3, m-1)
+	}
+}
+
+func ExampleCRT() {
+	// x ≡ 3 (mod 4) and x ≡ 5 (mod 6): the moduli share 2, and 3 ≡ 5 (mod 2).
+	fmt.Println(CRT([]uint64{3, 5}, []uint64{4, 6}))
+	// Output: 11 12 true
+}
+
+func ExampleMontgomery() {
+	mt := NewMontgomery(1<<61 - 1)
+	x := mt.To(3)
+	fmt.Println(mt.From(mt.Pow(x, 1<<61-2))) // Fermat: 3^(p-1) ≡ 1 for prime p
+	// Output: 1
+}
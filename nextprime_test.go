@@ -0,0 +1,107 @@
+package sieve
+
+import (
+	"fmt"
+	"testing"
+)
+
+var nextTests = []struct {
+	n    uint64
+	next uint64 // smallest prime > n, or 0 if none fits in a uint64
+	prev uint64 // largest prime < n, or 0 if none exists
+}{
+	{0, 2, 0},
+	{1, 2, 0},
+	{2, 3, 0},
+	{3, 5, 2},
+	{4, 5, 3},
+	{100, 101, 97},
+	{101, 103, 97},
+	{102, 103, 101},
+	{1000000, 1000003, 999983},
+	{1 << 32, 4294967311, 4294967291},
+	{1000000000000, 1000000000039, 999999999989},
+	{1425172824437699411, 1425172824437700887, 1425172824437699353}, // maximal gap of 1476 (Nicely)
+	{1 << 63, 9223372036854775837, 9223372036854775783},
+	{18446744073709551533, 18446744073709551557, 18446744073709551521},
+	{18446744073709551556, 18446744073709551557, 18446744073709551533},
+	{18446744073709551557, 0, 18446744073709551533}, // 2^64-59 is the largest 64-bit prime
+	{18446744073709551615, 0, 18446744073709551557},
+}
+
+// Are next and previous primes right both inside and beyond sieves of various sizes?
+func TestNextPrime(t *testing.T) {
+	for _, size := range []int{0, 10, 1000, 1000000} {
+		s := New(size)
+		for i, a := range nextTests {
+			if next := s.NextPrime(a.n); next != a.next {
+				t.Errorf("#%d, New(%d).NextPrime(%d) = %d; want %d", i, size, a.n, next, a.next)
+			}
+			if prev := s.PrevPrime(a.n); prev != a.prev {
+				t.Errorf("#%d, New(%d).PrevPrime(%d) = %d; want %d", i, size, a.n, prev, a.prev)
+			}
+		}
+	}
+}
+
+// Do NextPrime and PrevPrime agree with the table as they cross the sieve's edge?
+func TestNextPrimeEdge(t *testing.T) {
+	small, large := New(1000), New(20000)
+	for n := 0; n <= 10000; n++ {
+		next, prev := small.NextPrime(uint64(n)), small.PrevPrime(uint64(n))
+		if next <= uint64(n) || !large.Prime(int(next)) {
+			t.Errorf("NextPrime(%d) = %d; not the next prime", n, next)
+		}
+		if n > 2 && (prev >= uint64(n) || !large.Prime(int(prev))) {
+			t.Errorf("PrevPrime(%d) = %d; not the previous prime", n, prev)
+		}
+		for m := n + 1; m < int(next); m++ {
+			if large.Prime(m) {
+				t.Errorf("NextPrime(%d) = %d; skipped %d", n, next, m)
+			}
+		}
+		for m := int(prev) + 1; m < n; m++ {
+			if large.Prime(m) {
+				t.Errorf("PrevPrime(%d) = %d; skipped %d", n, prev, m)
+			}
+		}
+	}
+}
+
+// Is the deterministic test right near the notorious strong pseudoprimes?
+func TestIsPrime64(t *testing.T) {
+	composites := []uint64{
+		2047, 1373653, 25326001, 3215031751, 2152302898747, 3474749660383,
+		341550071728321, 3825123056546413051, // strong pseudoprimes to the first 9 primes
+		18446744073709551615, 1,
+	}
+	for _, n := range composites {
+		if isPrime64(n) {
+			t.Errorf("isPrime64(%d) = true; want false", n)
+		}
+	}
+	s := New(100000)
+	for n := 0; n <= 100000; n++ {
+		if isPrime64(uint64(n)) != s.Prime(n) {
+			t.Errorf("isPrime64(%d) = %v; want %v", n, !s.Prime(n), s.Prime(n))
+		}
+	}
+}
+
+func BenchmarkNextPrime(b *testing.B) {
+	b.StopTimer()
+	s := New(65536)
+	b.StartTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.NextPrime(1<<62 + uint64(i)*1000)
+	}
+}
+
+func ExampleSieve_NextPrime() {
+	// Find primes on either side of 10^12 with a small sieve.
+	s := New(1000)
+	fmt.Println(s.PrevPrime(1000000000000), s.NextPrime(1000000000000))
+	// Output:
+	// 999999999989 1000000000039
+}
@@ -0,0 +1,224 @@
+package sieve
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Sequence is an entry of the On-Line Encyclopedia of Integer Sequences computed with a
+// sieve. Its terms are a(Offset), a(Offset+1), ... as in the encyclopedia.
+type Sequence struct {
+	ID     string                              // A-number, as in "A000040"
+	Name   string                              // the encyclopedia's name, abbreviated
+	Offset int                                 // index of the first term
+	Terms  func(sieve *Sieve, count int) []int // the first count terms, or as many as the sieve can compute
+}
+
+// registry maps A-numbers to sequences.
+var registry = make(map[string]*Sequence)
+
+// Register adds a sequence to the registry, replacing any with the same A-number. The
+// registry is not synchronized: Register is meant for init functions, and must not be
+// called concurrently with itself, Lookup or Sequences.
+func Register(seq *Sequence) {
+	registry[seq.ID] = seq
+}
+
+// Lookup finds a registered sequence by A-number, accepting forms like "A000040",
+// "a40" and "40".
+func Lookup(id string) (*Sequence, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(id), "A"))
+	if err != nil {
+		return nil, false
+	}
+	seq, ok := registry[fmt.Sprintf("A%06d", n)]
+	return seq, ok
+}
+
+// Sequences returns the registered sequences in order of A-number.
+func Sequences() []*Sequence {
+	var list []*Sequence
+	for _, seq := range registry {
+		list = append(list, seq)
+	}
+	slices.SortFunc(list, func(a, b *Sequence) int { return strings.Compare(a.ID, b.ID) })
+	return list
+}
+
+// indexed builds the terms of a sequence defined by a(n) for n >= offset, stopping
+// early where a reports false for a number too big for the sieve.
+func indexed(offset int, a func(sieve *Sieve, n int) (int, bool)) func(*Sieve, int) []int {
+	return func(sieve *Sieve, count int) []int {
+		var terms []int
+		for n := offset; len(terms) < count; n++ {
+			v, ok := a(sieve, n)
+			if !ok {
+				break
+			}
+			terms = append(terms, v)
+		}
+		return terms
+	}
+}
+
+// streamed builds the terms of a sequence of primes produced by stages applied to All().
+func streamed(stages ...Stage) func(*Sieve, int) []int {
+	return func(sieve *Sieve, count int) []int {
+		return slices.Collect(Compose(sieve.All(), append(stages, Take(count))...))
+	}
+}
+
+// factorable wraps an arithmetic function that the sieve computes for n <= Size()^2.
+func factorable(f func(sieve *Sieve, n int) int) func(*Sieve, int) (int, bool) {
+	return func(sieve *Sieve, n int) (int, bool) {
+		if !sieve.reaches(n) { // too big for sieve?
+			return 0, false
+		}
+		return f(sieve, n), true
+	}
+}
+
+func init() {
+	for _, seq := range []*Sequence{
+		{"A000005", "d(n), the number of divisors of n", 1, indexed(1, factorable((*Sieve).DivisorCount))},
+		{"A000010", "Euler totient function phi(n)", 1, indexed(1, factorable((*Sieve).Totient))},
+		{"A000040", "The prime numbers", 1, streamed()},
+		{"A000203", "sigma(n), the sum of the divisors of n", 1, indexed(1, factorable((*Sieve).SumDivisors))},
+		{"A000720", "pi(n), the number of primes <= n", 1, func(sieve *Sieve, count int) []int {
+			terms, pi := make([]int, max(count, 0)), 0
+			for n := 1; n <= count; n++ {
+				if sieve.isPrime(uint64(n)) {
+					pi++
+				}
+				terms[n-1] = pi
+			}
+			return terms
+		}},
+		{"A001097", "Twin primes", 1, func(sieve *Sieve, count int) []int {
+			var terms []int
+			for p := range sieve.All() {
+				if len(terms) >= count {
+					break
+				}
+				if isPrime64(uint64(p-2)) || isPrime64(uint64(p+2)) {
+					terms = append(terms, p)
+				}
+			}
+			return terms
+		}},
+		{"A001221", "omega(n), the number of distinct primes dividing n", 1, indexed(1, factorable(func(sieve *Sieve, n int) int {
+			if n == 1 {
+				return 0
+			}
+			return len(sieve.FactorUnique(n))
+		}))},
+		{"A001222", "bigomega(n), the number of prime divisors of n counted with multiplicity", 1, indexed(1, factorable(func(sieve *Sieve, n int) int {
+			if n == 1 {
+				return 0
+			}
+			return len(sieve.Factor(n))
+		}))},
+		{"A001359", "Lesser of twin primes", 1, streamed(Pairs(2))},
+		{"A001913", "Full reptend primes: primes with primitive root 10", 1, func(sieve *Sieve, count int) []int {
+			return slices.Collect(Compose(sieve.FullReptendPrimes(10, 2, sieve.reach()), Take(count)))
+		}},
+		{"A002322", "Carmichael's lambda function", 1, indexed(1, factorable((*Sieve).CarmichaelLambda))},
+		{"A005117", "Squarefree numbers", 1, func(sieve *Sieve, count int) []int {
+			var terms []int
+			for n := 1; len(terms) < count && sieve.reaches(n); n++ {
+				if sieve.SquareFree(n) {
+					terms = append(terms, n)
+				}
+			}
+			return terms
+		}},
+		{"A005384", "Sophie Germain primes", 1, streamed(Filter(func(p int) bool { return isPrime64(uint64(2*p + 1)) }))},
+		{"A006450", "Prime-indexed primes", 1, streamed(PrimeIndexed(1))},
+		{"A007504", "Sum of the first n primes", 0, func(sieve *Sieve, count int) []int {
+			terms, sum := []int{0}, 0
+			for p := range Compose(sieve.All(), Take(count-1)) {
+				sum += p
+				terms = append(terms, sum)
+			}
+			return terms[:min(max(count, 0), len(terms))]
+		}},
+		{"A008683", "Moebius (or Mobius) function mu(n)", 1, indexed(1, factorable((*Sieve).Mobius))},
+		{"A046145", "Smallest primitive root of n, or 0 if no root exists", 1, indexed(1, factorable((*Sieve).PrimitiveRoot))},
+		{"A051626", "Period of decimal representation of 1/n, or 0 if 1/n terminates", 1, indexed(1, factorable((*Sieve).DecimalPeriod))},
+		{"A104272", "Ramanujan primes", 1, streamed(Ramanujan)},
+	} {
+		Register(seq)
+	}
+}
+
+// WriteBFile writes the first count terms in the encyclopedia's b-file format: a comment
+// line naming the sequence, then one "n a(n)" line per term.
+func (seq *Sequence) WriteBFile(w io.Writer, sieve *Sieve, count int) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "# %s %s\n", seq.ID, seq.Name)
+	for i, v := range seq.Terms(sieve, count) {
+		fmt.Fprintf(b, "%d %d\n", seq.Offset+i, v)
+	}
+	return b.Flush()
+}
+
+// ReadBFile parses a b-file, returning the index of its first term and the terms, which
+// may be of any size. Comment lines beginning with '#' and blank lines are skipped; the
+// indices of the remaining lines must be consecutive.
+func ReadBFile(r io.Reader) (offset int, terms []*big.Int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt) // lines grow with the terms
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return 0, nil, fmt.Errorf("sieve: b-file line %d: want \"n a(n)\", have %q", line, text)
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return 0, nil, fmt.Errorf("sieve: b-file line %d: %v", line, err)
+		}
+		v, ok := new(big.Int).SetString(fields[1], 10)
+		if !ok {
+			return 0, nil, fmt.Errorf("sieve: b-file line %d: invalid term %q", line, fields[1])
+		}
+		if len(terms) == 0 {
+			offset = n
+		} else if n != offset+len(terms) {
+			return 0, nil, fmt.Errorf("sieve: b-file line %d: index %d follows %d", line, n, offset+len(terms)-1)
+		}
+		terms = append(terms, v)
+	}
+	return offset, terms, scanner.Err()
+}
+
+// Validate compares a b-file, such as one downloaded from the encyclopedia, with the
+// terms computed by the sieve, returning an error that describes the first difference.
+func (seq *Sequence) Validate(r io.Reader, sieve *Sieve) error {
+	offset, want, err := ReadBFile(r)
+	if err != nil {
+		return err
+	}
+	if offset != seq.Offset {
+		return fmt.Errorf("sieve: %s: b-file begins at a(%d); want a(%d)", seq.ID, offset, seq.Offset)
+	}
+	have := seq.Terms(sieve, len(want))
+	for i := range want {
+		if i >= len(have) {
+			return fmt.Errorf("sieve: %s: sieve of size %d computes %d of %d terms", seq.ID, sieve.Size(), len(have), len(want))
+		}
+		if big.NewInt(int64(have[i])).Cmp(want[i]) != 0 {
+			return fmt.Errorf("sieve: %s: a(%d) = %d; b-file has %d", seq.ID, offset+i, have[i], want[i])
+		}
+	}
+	return nil
+}
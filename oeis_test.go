@@ -0,0 +1,123 @@
+package sieve
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The files in testdata/reference are in b-file format but were not downloaded from the
+// OEIS: each was computed by a separate trial-division program, so they check the sieve
+// against an independent reference rather than against the encyclopedia itself.
+func TestReferenceFiles(t *testing.T) {
+	sieve := New(1 << 20)
+	files, err := filepath.Glob(filepath.Join("testdata", "reference", "A*.txt"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no reference files in testdata: %v", err)
+	}
+	for i, name := range files {
+		id := strings.TrimSuffix(filepath.Base(name), ".txt")
+		seq, ok := Lookup(id)
+		if !ok {
+			t.Errorf("#%d, Lookup(%q) failed", i, id)
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := seq.Validate(f, sieve); err != nil {
+			t.Errorf("#%d, %s: %v", i, name, err)
+		}
+		f.Close()
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for i, id := range []string{"A000040", "a000040", "A40", "40"} {
+		if seq, ok := Lookup(id); !ok || seq.ID != "A000040" {
+			t.Errorf("#%d, Lookup(%q) failed", i, id)
+		}
+	}
+	for i, id := range []string{"", "A", "Aprime", "A999999"} {
+		if _, ok := Lookup(id); ok {
+			t.Errorf("#%d, Lookup(%q) succeeded", i, id)
+		}
+	}
+	list := Sequences()
+	if !slices.IsSortedFunc(list, func(a, b *Sequence) int { return strings.Compare(a.ID, b.ID) }) {
+		t.Errorf("Sequences() not in order")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	sieve := New(1000)
+	for i, seq := range Sequences() {
+		var b bytes.Buffer
+		if err := seq.WriteBFile(&b, sieve, 100); err != nil {
+			t.Fatal(err)
+		}
+		offset, terms, err := ReadBFile(&b)
+		if err != nil || offset != seq.Offset || len(terms) != 100 {
+			t.Errorf("#%d, %s round trip: offset %d, %d terms, %v", i, seq.ID, offset, len(terms), err)
+		}
+		for _, count := range []int{0, -1} {
+			if terms := seq.Terms(sieve, count); len(terms) != 0 {
+				t.Errorf("#%d, %s has %d terms for count %d", i, seq.ID, len(terms), count)
+			}
+		}
+	}
+}
+
+func TestSumPrimes(t *testing.T) {
+	seq, _ := Lookup("A007504")
+	sums := seq.Terms(New(1<<16), 100001)
+	for i, s := range sumTests {
+		if s.count < len(sums) && uint64(sums[s.count]) != s.sum {
+			t.Errorf("#%d, A007504(%d) = %d; want %d", i, s.count, sums[s.count], s.sum)
+		}
+	}
+}
+
+func TestReadBFile(t *testing.T) {
+	for i, text := range []string{
+		"1 2\n3 5\n",
+		"1 2 3\n",
+		"1 two\n",
+		"x 2\n",
+	} {
+		if _, _, err := ReadBFile(strings.NewReader(text)); err == nil {
+			t.Errorf("#%d, ReadBFile(%q) succeeded", i, text)
+		}
+	}
+	if _, terms, err := ReadBFile(strings.NewReader("1 2\n2 36893488147419103363\n")); err != nil || terms[1].String() != "36893488147419103363" {
+		t.Errorf("ReadBFile with a term beyond 2^64 = %v, %v", terms, err)
+	}
+	huge := "1" + strings.Repeat("0", 100000)
+	if _, terms, err := ReadBFile(strings.NewReader("1 2\n2 " + huge + "\n")); err != nil || len(terms) != 2 || terms[1].String() != huge {
+		t.Errorf("ReadBFile with a term of 100001 digits: %d terms, %v", len(terms), err)
+	}
+	seq, _ := Lookup("A000040")
+	if err := seq.Validate(strings.NewReader("1 2\n2 3\n3 7\n"), New(100)); err == nil {
+		t.Errorf("Validate accepted a wrong term")
+	}
+}
+
+func ExampleSequence_WriteBFile() {
+	seq, _ := Lookup("A8683")
+	seq.WriteBFile(os.Stdout, New(100), 6)
+	fmt.Println(seq.Terms(New(100), 12))
+	// Output:
+	// # A008683 Moebius (or Mobius) function mu(n)
+	// 1 1
+	// 2 -1
+	// 3 -1
+	// 4 0
+	// 5 -1
+	// 6 1
+	// [1 -1 -1 0 -1 1 -1 0 0 1 -1 0]
+}
@@ -0,0 +1,104 @@
+package sieve
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var pairTests = []struct {
+	lo, hi, q, primes int
+	counts            [][]int
+}{
+	{0, 1000000, 3, 78498, [][]int{{0, 0, 1}, {0, 16394, 22837}, {1, 22837, 16427}}},
+	{0, 1000000, 10, 78498, [][]int{
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 3213, 0, 6299, 0, 0, 0, 6550, 0, 3555},
+		{0, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+		{0, 4387, 0, 2810, 0, 1, 0, 5819, 0, 6647},
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0, 0, 1, 0, 0},
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 5069, 0, 5443, 0, 0, 0, 2873, 0, 6236},
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 6948, 0, 5112, 0, 0, 0, 4378, 0, 3155},
+	}},
+	{1000000000000, 1000001000000, 4, 36249, [][]int{{0, 0, 0, 0}, {0, 8388, 0, 9763}, {0, 0, 0, 0}, {0, 9763, 0, 8334}}},
+}
+
+func TestResiduePairs(t *testing.T) {
+	sieve := New(1000000)
+	for i, a := range pairTests {
+		stats := sieve.ResiduePairs(a.lo, a.hi, a.q)
+		if stats.Primes != a.primes {
+			t.Errorf("#%d, [%d, %d] has %d primes; want %d", i, a.lo, a.hi, stats.Primes, a.primes)
+		}
+		tr := stats.Transitions[0]
+		if !slices.EqualFunc(tr.Counts, a.counts, slices.Equal) {
+			t.Errorf("#%d, mod %d transitions %v; want %v", i, a.q, tr.Counts, a.counts)
+		}
+	}
+}
+
+func TestResiduePairsTogether(t *testing.T) {
+	sieve := New(1000)
+	stats := sieve.ResiduePairs(0, 1000000, 10, 3)
+	for i, q := range []int{10, 3} {
+		want := pairTests[(i+1)%2].counts
+		if tr := stats.Transitions[i]; tr.Modulus != q || !slices.EqualFunc(tr.Counts, want, slices.Equal) {
+			t.Errorf("#%d, mod %d transitions %v; want %v", i, q, tr.Counts, want)
+		}
+	}
+	tr := stats.Transitions[0]
+	if tr.Pairs != 78494 || tr.Expected != 78494.0/16 {
+		t.Errorf("mod 10, %d pairs expecting %v each; want 78494, %v", tr.Pairs, tr.Expected, 78494.0/16)
+	}
+	if d := tr.Deviation[1][1]; d > -0.34 || d < -0.35 {
+		t.Errorf("mod 10, deviation of (1, 1) is %v; want -0.345", d)
+	}
+	if sieve.ResiduePairs(0, 100, 10, 1) != nil {
+		t.Errorf("ResiduePairs accepted modulus 1")
+	}
+}
+
+func TestResiduePairsOutput(t *testing.T) {
+	stats := New(1000).ResiduePairs(0, 1000, 4)
+	var b bytes.Buffer
+	if err := stats.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var decoded PairStats
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(decoded.Transitions[0].Counts, stats.Transitions[0].Counts, slices.Equal) {
+		t.Errorf("JSON round trip changed counts")
+	}
+	b.Reset()
+	if err := stats.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(b.String(), "\n"); lines != 5 {
+		t.Errorf("CSV has %d lines; want 5", lines)
+	}
+}
+
+func BenchmarkResiduePairs(b *testing.B) {
+	sieve := New(1000000)
+	for b.Loop() {
+		sieve.ResiduePairs(0, 1000000, 3, 4, 10)
+	}
+}
+
+func ExamplePairStats_WriteCSV() {
+	New(1000000).ResiduePairs(0, 1000000, 3).WriteCSV(os.Stdout)
+	// Output:
+	// modulus,a,b,count,expected,deviation
+	// 3,1,1,16394,19623.750,-0.164584
+	// 3,1,2,22837,19623.750,0.163743
+	// 3,2,1,22837,19623.750,0.163743
+	// 3,2,2,16427,19623.750,-0.162902
+}
@@ -0,0 +1,320 @@
+package sieve
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// PolynomialCount reports how many values of a polynomial are prime, with the number
+// that the Bateman-Horn conjecture (1962) predicts.
+type PolynomialCount struct {
+	Count     int     // n in the range for which f(n) is prime
+	Predicted float64 // C·Σ 1/ln f(n) over the n with f(n) > 1
+	Constant  float64 // C = Π (1 - ω(p)/p)/(1 - 1/p), where ω(p) counts the roots of f mod p
+}
+
+const (
+	polynomialWidth = 1 << 16 // arguments sieved at a time
+	polynomialReach = 1 << 12 // primes sieved whatever the width of the range
+)
+
+// PolynomialPrimes counts the n in [nLo, nHi] for which f(n) is prime, where poly lists
+// the coefficients of f lowest first, so that {-1, 0, 2} is 2n²-1 (Project Euler 216).
+// As in the quadratic sieve, the values are not tested one by one: for each prime p the
+// roots of f modulo p are found, and every n congruent to a root is struck since p
+// divides f(n). The survivors are prime when the sieve reaches the square root of the
+// largest value and are confirmed individually when it does not. It returns nil when
+// f(n) might not fit in an int64 for some n in the range.
+//
+// The Bateman-Horn constant is the product over the primes used for sieving, which are
+// those up to the larger of the width of the range and a few thousand. It is 0
+// when f has a fixed prime divisor, like n²+n+2, which is always even.
+func (sieve *Sieve) PolynomialPrimes(poly []int64, nLo, nHi int) *PolynomialCount {
+	for len(poly) > 0 && poly[len(poly)-1] == 0 {
+		poly = poly[:len(poly)-1]
+	}
+	bound, ok := polynomialBound(poly, nLo, nHi)
+	if !ok {
+		return nil
+	}
+	result := &PolynomialCount{Constant: 1}
+	if nHi < nLo || len(poly) == 0 {
+		return result
+	}
+
+	// find the roots of f modulo each prime up to the square root of the largest value,
+	// though not primes so much larger than the range that they strike little
+	root := isqrt64(bound)
+	limit := min(root, uint64(sieve.size), uint64(max(polynomialReach, nHi-nLo+1)))
+	exact := limit == root
+	type rooted struct {
+		p     int
+		roots []uint64
+	}
+	var primes []rooted
+	for p := range sieve.primes(2, int(limit)) {
+		roots := polynomialRoots(poly, uint64(p))
+		result.Constant *= (1 - float64(len(roots))/float64(p)) / (1 - 1/float64(p))
+		if len(roots) > 0 {
+			primes = append(primes, rooted{p, roots})
+		}
+	}
+
+	struck := make([]bool, polynomialWidth)
+	for lo := nLo; lo <= nHi; lo += polynomialWidth {
+		n := min(polynomialWidth, nHi-lo+1)
+		clear(struck[:n])
+		for _, r := range primes {
+			p := r.p
+			for _, x := range r.roots {
+				for i := ((int(x)-lo)%p + p) % p; i < n; i += p {
+					struck[i] = true // p divides f(lo+i)
+				}
+			}
+		}
+		for i := range n {
+			v := polynomialValue(poly, lo+i)
+			if v < 2 {
+				continue
+			}
+			result.Predicted += 1 / math.Log(float64(v))
+			switch {
+			case uint64(v) <= limit: // might equal the prime that struck it
+				if sieve.isPrime(uint64(v)) {
+					result.Count++
+				}
+			case struck[i]:
+			case exact || isPrime64(uint64(v)):
+				result.Count++
+			}
+		}
+	}
+	result.Predicted *= result.Constant
+	return result
+}
+
+// polynomialBound returns Σ |a_i| m^i, where m is the larger of |nLo| and |nHi|, which
+// bounds |f(n)| on the range, reporting false if it exceeds the largest int64.
+func polynomialBound(poly []int64, nLo, nHi int) (uint64, bool) {
+	m := max(magnitude(int64(nLo)), magnitude(int64(nHi)))
+	var bound uint64
+	for i := len(poly) - 1; i >= 0; i-- { // Horner's rule on magnitudes
+		hi, lo := bits.Mul64(bound, m)
+		sum, carry := bits.Add64(lo, magnitude(poly[i]), 0)
+		if hi != 0 || carry != 0 || sum > math.MaxInt64 {
+			return 0, false
+		}
+		bound = sum
+	}
+	return bound, true
+}
+
+// magnitude returns |a| as a uint64.
+func magnitude(a int64) uint64 {
+	if a < 0 {
+		return -uint64(a)
+	}
+	return uint64(a)
+}
+
+// polynomialValue returns f(n) by Horner's rule.
+func polynomialValue(poly []int64, n int) int64 {
+	var v int64
+	for i := len(poly) - 1; i >= 0; i-- {
+		v = v*int64(n) + poly[i]
+	}
+	return v
+}
+
+// polynomialRoots returns the distinct roots of f modulo the prime p, all of 0..p-1 if
+// p divides every coefficient. Linear and quadratic polynomials are solved directly,
+// small primes by trying every residue, and the rest by Cantor-Zassenhaus: the roots of
+// f are those of gcd(f, x^p - x), which splits into factors of lower degree on taking
+// gcds with (x+a)^((p-1)/2) - 1 for a few a.
+func polynomialRoots(poly []int64, p uint64) []uint64 {
+	f := make([]uint64, len(poly))
+	for i, a := range poly {
+		f[i] = uint64((a%int64(p) + int64(p)) % int64(p))
+	}
+	f = trim(f)
+	var roots []uint64
+	switch {
+	case len(f) == 0:
+		for x := range p {
+			roots = append(roots, x)
+		}
+	case len(f) == 1:
+	case p < 64:
+		for x := range p {
+			v := uint64(0)
+			for i := len(f) - 1; i >= 0; i-- {
+				v = (v*x + f[i]) % p
+			}
+			if v == 0 {
+				roots = append(roots, x)
+			}
+		}
+	case len(f) == 2:
+		f = monic(f, p)
+		roots = append(roots, (p-f[0])%p)
+	case len(f) == 3: // x² + bx + c = 0 when x = (-b ± √(b²-4c))/2
+		f = monic(f, p)
+		b, c := f[1], f[0]
+		d := (modarith.MulMod(b, b, p) + p - modarith.MulMod(4, c, p)) % p
+		half := (p + 1) / 2
+		if d == 0 {
+			return []uint64{modarith.MulMod(p-b, half, p)}
+		}
+		s, ok := sqrtMod(d, p)
+		if ok {
+			roots = append(roots, modarith.MulMod((p-b+s)%p, half, p), modarith.MulMod((2*p-b-s)%p, half, p))
+		}
+	default:
+		f = monic(f, p)
+		t := polyPowMod([]uint64{0, 1}, p, f, p) // x^p mod f
+		t = append(t, make([]uint64, max(0, 2-len(t)))...)
+		t[1] = (t[1] + p - 1) % p
+		roots = splitRoots(polyGCD(f, trim(t), p), p, roots)
+	}
+	return roots
+}
+
+// splitRoots appends the roots of g, a monic product of distinct linear factors mod p.
+func splitRoots(g []uint64, p uint64, roots []uint64) []uint64 {
+	switch len(g) {
+	case 0, 1:
+		return roots
+	case 2:
+		return append(roots, (p-g[0])%p)
+	}
+	for a := uint64(1); ; a++ {
+		h := polyPowMod([]uint64{a % p, 1}, (p-1)/2, g, p)
+		if len(h) == 0 {
+			continue
+		}
+		h[0] = (h[0] + p - 1) % p
+		d := polyGCD(g, trim(h), p)
+		if 1 < len(d) && len(d) < len(g) {
+			q, _ := polyDivMod(g, d, p)
+			return splitRoots(q, p, splitRoots(d, p, roots))
+		}
+	}
+}
+
+// sqrtMod returns a square root of a modulo the odd prime p by the Tonelli-Shanks
+// algorithm, reporting false if a is not a quadratic residue.
+func sqrtMod(a, p uint64) (uint64, bool) {
+	if modarith.PowMod(a, (p-1)/2, p) != 1 {
+		return 0, false
+	}
+	q, s := p-1, 0
+	for q&1 == 0 {
+		q, s = q>>1, s+1
+	}
+	z := uint64(2)
+	for modarith.PowMod(z, (p-1)/2, p) != p-1 {
+		z++ // a non-residue
+	}
+	c, t, r := modarith.PowMod(z, q, p), modarith.PowMod(a, q, p), modarith.PowMod(a, (q+1)/2, p)
+	for m := s; t != 1; {
+		i, u := 0, t
+		for u != 1 {
+			u, i = modarith.MulMod(u, u, p), i+1
+		}
+		b := c
+		for range m - i - 1 {
+			b = modarith.MulMod(b, b, p)
+		}
+		m, c = i, modarith.MulMod(b, b, p)
+		t, r = modarith.MulMod(t, c, p), modarith.MulMod(r, b, p)
+	}
+	return r, true
+}
+
+// Polynomials mod p are slices of coefficients lowest first, without high zeros, so
+// that the zero polynomial is empty.
+
+// trim removes high zero coefficients.
+func trim(a []uint64) []uint64 {
+	for len(a) > 0 && a[len(a)-1] == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+// monic scales a nonzero a so that its leading coefficient is 1.
+func monic(a []uint64, p uint64) []uint64 {
+	inv, _ := modarith.InvMod(a[len(a)-1], p)
+	b := make([]uint64, len(a))
+	for i, c := range a {
+		b[i] = modarith.MulMod(c, inv, p)
+	}
+	return b
+}
+
+// polyDivMod divides a by the monic m, returning quotient and remainder.
+func polyDivMod(a, m []uint64, p uint64) (q, r []uint64) {
+	r = append([]uint64(nil), a...)
+	if len(r) < len(m) {
+		return nil, r
+	}
+	q = make([]uint64, len(r)-len(m)+1)
+	for i := len(r) - 1; i >= len(m)-1; i-- {
+		c := r[i]
+		if c == 0 {
+			continue
+		}
+		k := i - (len(m) - 1)
+		q[k] = c
+		for j, b := range m {
+			r[k+j] = (r[k+j] + p - modarith.MulMod(c, b, p)) % p
+		}
+	}
+	return trim(q), trim(r[:len(m)-1])
+}
+
+// polyGCD returns the monic greatest common divisor of a and b.
+func polyGCD(a, b []uint64, p uint64) []uint64 {
+	for len(b) > 0 {
+		b = monic(b, p)
+		_, r := polyDivMod(a, b, p)
+		a, b = b, r
+	}
+	if len(a) == 0 {
+		return a
+	}
+	return monic(a, p)
+}
+
+// polyMulMod returns a·b modulo the monic m.
+func polyMulMod(a, b, m []uint64, p uint64) []uint64 {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	c := make([]uint64, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			c[i+j] = (c[i+j] + modarith.MulMod(x, y, p)) % p
+		}
+	}
+	_, r := polyDivMod(trim(c), m, p)
+	return r
+}
+
+// polyPowMod returns a^e modulo the monic m.
+func polyPowMod(a []uint64, e uint64, m []uint64, p uint64) []uint64 {
+	_, a = polyDivMod(a, m, p)
+	r := []uint64{1}
+	if len(m) == 1 {
+		r = nil // everything is 0 mod a constant
+	}
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = polyMulMod(r, a, m, p)
+		}
+		a = polyMulMod(a, a, m, p)
+	}
+	return r
+}
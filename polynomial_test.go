@@ -0,0 +1,98 @@
+package sieve
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+var polynomialTests = []struct {
+	poly     []int64
+	nLo, nHi int
+	count    int
+}{
+	{[]int64{-1, 0, 2}, 2, 10000, 2202}, // the Euler test: 2n²-1
+	{[]int64{-1, 0, 2}, 2, 100000, 17185},
+	{[]int64{-1, 0, 2}, 2, 1000000, 141444},
+	{[]int64{1, 0, 1}, 1, 100000, 6656}, // Landau's fourth problem: n²+1
+	{[]int64{2, 0, 0, 1}, 1, 20000, 993},
+	{[]int64{1, 0, 0, 0, 1}, 1, 20000, 1466},
+	{[]int64{1, 6}, 0, 100000, 24524},
+	{[]int64{-7, 0, 1}, -300, 300, 54},
+	{[]int64{41, 1, 1}, 0, 1000, 582}, // Euler's n²+n+41
+	{[]int64{2, 1, 1}, 0, 100, 1},     // always even
+	{[]int64{1, 1, 1, 1, 1, 1, 1}, -1000, 1000, 264},
+	{[]int64{-5, 3, -2, 0, 1}, -2000, 2000, 196},
+	{[]int64{7}, 0, 10, 11},
+	{[]int64{}, 0, 10, 0},
+}
+
+func TestPolynomialPrimes(t *testing.T) {
+	for _, size := range []int{100, 1500000} {
+		sieve := New(size)
+		for i, a := range polynomialTests {
+			if size == 100 && a.nHi-a.nLo > 20000 {
+				continue
+			}
+			result := sieve.PolynomialPrimes(a.poly, a.nLo, a.nHi)
+			if result.Count != a.count {
+				t.Errorf("#%d, size %d, %v on [%d, %d] has %d primes; want %d", i, size, a.poly, a.nLo, a.nHi, result.Count, a.count)
+			}
+		}
+	}
+}
+
+func TestPolynomialPredicted(t *testing.T) {
+	sieve := New(1000000)
+	result := sieve.PolynomialPrimes([]int64{1, 0, 1}, 1, 1000000)
+	if math.Abs(result.Constant-1.3728) > 0.01 {
+		t.Errorf("n²+1 constant = %v; want 1.3728", result.Constant)
+	}
+	if math.Abs(result.Predicted/float64(result.Count)-1) > 0.01 {
+		t.Errorf("n²+1 predicted %v primes; have %d", result.Predicted, result.Count)
+	}
+	if c := sieve.PolynomialPrimes([]int64{2, 1, 1}, 0, 100).Constant; c != 0 {
+		t.Errorf("n²+n+2 constant = %v; want 0", c)
+	}
+	if sieve.PolynomialPrimes([]int64{1, 0, 1}, 0, 1<<32) != nil {
+		t.Errorf("n²+1 accepted beyond int64")
+	}
+}
+
+func TestPolynomialRoots(t *testing.T) {
+	polys := [][]int64{{-1, 0, 2}, {1, 0, 1}, {2, 0, 0, 1}, {1, 0, 0, 0, 1}, {-5, 3, -2, 0, 1}, {0, -1, 0, 0, 0, 0, 1}, {6, 11, 6, 1}, {-1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}}
+	for i, poly := range polys {
+		for p := range New(2000).primes(2, 2000) {
+			var want []uint64
+			for x := range p {
+				v := int64(0) // Horner's rule mod p
+				for i := len(poly) - 1; i >= 0; i-- {
+					v = (v*int64(x) + poly[i]) % int64(p)
+				}
+				if v == 0 {
+					want = append(want, uint64(x))
+				}
+			}
+			roots := polynomialRoots(poly, uint64(p))
+			slices.Sort(roots)
+			if !slices.Equal(roots, want) {
+				t.Errorf("#%d, roots of %v mod %d = %v; want %v", i, poly, p, roots, want)
+			}
+		}
+	}
+}
+
+func BenchmarkPolynomialPrimes(b *testing.B) {
+	sieve := New(1500000)
+	for b.Loop() {
+		sieve.PolynomialPrimes([]int64{-1, 0, 2}, 2, 1000000)
+	}
+}
+
+func ExampleSieve_PolynomialPrimes() {
+	euler := New(100).PolynomialPrimes([]int64{41, 1, 1}, 0, 39)
+	fmt.Println(euler.Count)
+	// Output:
+	// 40
+}
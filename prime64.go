@@ -0,0 +1,90 @@
+package sieve
+
+import (
+	"math/bits"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// maxPrime64 is the largest prime representable in a uint64, 2^64-59.
+const maxPrime64 = 1<<64 - 59
+
+// The first twelve primes are sufficient Miller-Rabin witnesses for every n < 3.18*10^23
+// (Sorenson and Webster, 2015), which covers all 64-bit integers with room to spare.
+var witnesses64 = []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+
+// isPrime64 is a deterministic primality test for any uint64. Small divisors are
+// removed by trial division before the Miller-Rabin rounds.
+func isPrime64(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for _, p := range witnesses64 {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+	if n < 41*41 {
+		return true
+	}
+
+	mt := modarith.NewMontgomery(n)
+	for _, a := range witnesses64 {
+		if !strongProbableMontgomery(mt, a) {
+			return false
+		}
+	}
+	return true
+}
+
+// strongProbable64 reports whether the odd n > 2 passes the strong probable-prime test
+// (Miller-Rabin) to base a: with n-1 = d·2^s and d odd, either a^d ≡ 1 or
+// a^(d·2^r) ≡ -1 (mod n) for some r < s.
+func strongProbable64(n, a uint64) bool {
+	return strongProbableMontgomery(modarith.NewMontgomery(n), a)
+}
+
+// strongProbableMontgomery is strongProbable64 for the modulus of mt, squaring in
+// Montgomery form, where 1 and -1 are mt.One() and n - mt.One().
+func strongProbableMontgomery(mt *modarith.Montgomery, a uint64) bool {
+	n := mt.Modulus()
+	d := n - 1
+	s := bits.TrailingZeros64(d)
+	d >>= uint(s)
+	one, minus := mt.One(), n-mt.One()
+	x := mt.Pow(mt.To(a), d)
+	if x == one || x == minus {
+		return true
+	}
+	for r := 1; r < s; r++ {
+		x = mt.Mul(x, x)
+		if x == minus {
+			return true
+		}
+	}
+	return false
+}
+
+// isqrt64 returns floor(sqrt(n)).
+func isqrt64(n uint64) uint64 {
+	if n < 2 {
+		return n
+	}
+	r := uint64(1) << uint((bits.Len64(n)+1)/2) // r >= sqrt(n)
+	for {
+		s := (r + n/r) / 2 // Newton's step never overshoots downward
+		if s >= r {
+			return r
+		}
+		r = s
+	}
+}
+
+// isPrime tests primality of any uint64, by inspection when n is inside the sieve and by
+// the deterministic test otherwise.
+func (sieve *Sieve) isPrime(n uint64) bool {
+	if n <= uint64(sieve.size) {
+		return sieve.Prime(int(n))
+	}
+	return isPrime64(n)
+}
@@ -0,0 +1,130 @@
+package sieve
+
+import (
+	"iter"
+	"slices"
+)
+
+// Progression is the arithmetic progression Start, Start+Step, ..., with Length terms.
+type Progression struct {
+	Start, Step, Length int
+}
+
+// Last returns the final term of the progression.
+func (ap Progression) Last() int {
+	return ap.Start + (ap.Length-1)*ap.Step
+}
+
+// primorial returns the product of the primes <= k, or limit+1 if that is larger.
+func primorial(k, limit int) int {
+	product := 1
+	for p := 2; p <= k; p++ {
+		if isPrime64(uint64(p)) {
+			if product > limit/p {
+				return limit + 1
+			}
+			product *= p
+		}
+	}
+	return product
+}
+
+// steps returns, in increasing order, the steps of the k-term progressions of primes
+// that could end at the prime last, for k >= 2. Since k terms fill every residue class
+// modulo a prime q <= k unless q divides the step, and the only multiple of q that is
+// prime is q itself, the step is a multiple of the primorial k# unless the first term
+// is such a q, and then it is a multiple of k#/q (Green and Tao, 2004, show that such
+// progressions exist for every k).
+func steps(k, last int) []int {
+	step := primorial(k, last)
+	var candidates []int
+	for d := step; last-(k-1)*d > k; d += step {
+		candidates = append(candidates, d)
+	}
+	for q := 2; q <= k; q++ {
+		if isPrime64(uint64(q)) && (last-q)%(k-1) == 0 {
+			if d := (last - q) / (k - 1); d > 0 && d%(step/q) == 0 {
+				candidates = append(candidates, d)
+			}
+		}
+	}
+	slices.Sort(candidates)
+	return candidates
+}
+
+// run returns the number of consecutive primes among last, last-d, last-2d, ....
+func (sieve *Sieve) run(last, d, limit int) int {
+	n := 0
+	for t := last; n < limit && t >= 2 && sieve.isPrime(uint64(t)); t -= d {
+		n++
+	}
+	return n
+}
+
+// PrimeAPs returns an iterator over every arithmetic progression of k primes whose last
+// term is no more than maxPrime, in order of last term and then of step. Only steps that
+// are multiples of the primorial are tried, as explained for steps, and the terms are
+// tested in the table where they can be and individually where they cannot.
+func (sieve *Sieve) PrimeAPs(k, maxPrime int) iter.Seq[Progression] {
+	return func(yield func(Progression) bool) {
+		if k < 1 {
+			return
+		}
+		for last := range sieve.primes(2, maxPrime) {
+			if k == 1 {
+				if !yield(Progression{last, 0, 1}) {
+					return
+				}
+				continue
+			}
+			for _, d := range steps(k, last) {
+				if sieve.run(last-d, d, k-1) == k-1 && !yield(Progression{last - (k-1)*d, d, k}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MinimalPrimeAP returns the k-term progression of primes with the least last term (and
+// then the least step), if its last term is no more than maxPrime
+// (http://oeis.org/A005115).
+func (sieve *Sieve) MinimalPrimeAP(k, maxPrime int) (Progression, bool) {
+	for ap := range sieve.PrimeAPs(k, maxPrime) {
+		return ap, true
+	}
+	return Progression{}, false
+}
+
+// PrimeAPRecords returns an iterator over record progressions of primes: taking last
+// terms in increasing order up to maxPrime, it yields a progression whenever one is
+// longer than all those before. Each is the minimal progression of its length, and when
+// a record exceeds the previous one by more than one term it is also minimal for the
+// lengths between. The search for a longer progression tries only steps that are
+// multiples of the corresponding primorial, so it quickens as the records grow.
+func (sieve *Sieve) PrimeAPRecords(maxPrime int) iter.Seq[Progression] {
+	return func(yield func(Progression) bool) {
+		record := 0
+		for last := range sieve.primes(2, maxPrime) {
+			if record == 0 {
+				record = 1
+				if !yield(Progression{last, 0, 1}) {
+					return
+				}
+				continue
+			}
+			var best Progression
+			for _, d := range steps(record+1, last) {
+				if n := sieve.run(last, d, last); n > record && n > best.Length {
+					best = Progression{last - (n-1)*d, d, n}
+				}
+			}
+			if best.Length > 0 {
+				record = best.Length
+				if !yield(best) {
+					return
+				}
+			}
+		}
+	}
+}
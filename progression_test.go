@@ -0,0 +1,93 @@
+package sieve
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+var progressionTests = []struct {
+	k, maxPrime, count int
+}{
+	{1, 100, 25},
+	{2, 100, 300},
+	{3, 1000, 1500},
+	{4, 1000, 318},
+	{5, 10000, 1283},
+	{6, 10000, 263},
+	{0, 100, 0},
+}
+
+func TestPrimeAPs(t *testing.T) {
+	for _, size := range []int{10, 10000} {
+		sieve := New(size)
+		for i, a := range progressionTests {
+			count, prev := 0, Progression{}
+			for ap := range sieve.PrimeAPs(a.k, a.maxPrime) {
+				count++
+				if ap.Length != a.k || ap.Last() > a.maxPrime || ap.Last() < prev.Last() ||
+					ap.Last() == prev.Last() && ap.Step <= prev.Step {
+					t.Errorf("#%d, size %d, %v follows %v", i, size, ap, prev)
+				}
+				for j := range ap.Length {
+					if !sieve.isPrime(uint64(ap.Start + j*ap.Step)) {
+						t.Errorf("#%d, size %d, %v has composite term %d", i, size, ap, ap.Start+j*ap.Step)
+					}
+				}
+				prev = ap
+			}
+			if count != a.count {
+				t.Errorf("#%d, size %d, %d progressions of %d primes <= %d; want %d", i, size, count, a.k, a.maxPrime, a.count)
+			}
+		}
+	}
+}
+
+var minimalAPs = []Progression{
+	{2, 0, 1}, {2, 1, 2}, {3, 2, 3}, {5, 6, 4}, {5, 6, 5}, {7, 30, 6}, {7, 150, 7},
+	{199, 210, 8}, {199, 210, 9}, {199, 210, 10}, {110437, 13860, 11}, {110437, 13860, 12}, {4943, 60060, 13},
+}
+
+func TestMinimalPrimeAP(t *testing.T) {
+	sieve := New(1000000)
+	for i, want := range minimalAPs {
+		if ap, ok := sieve.MinimalPrimeAP(want.Length, 1000000); !ok || ap != want {
+			t.Errorf("#%d, MinimalPrimeAP(%d) = %v, %v; want %v", i, want.Length, ap, ok, want)
+		}
+	}
+	if ap, ok := sieve.MinimalPrimeAP(14, 1000000); ok {
+		t.Errorf("MinimalPrimeAP(14) = %v below 1000000", ap)
+	}
+}
+
+func TestPrimeAPRecords(t *testing.T) {
+	records := slices.Collect(New(1000).PrimeAPRecords(1000000))
+	if !slices.Equal(records, minimalAPs) {
+		t.Errorf("PrimeAPRecords(1000000) = %v; want %v", records, minimalAPs)
+	}
+}
+
+func BenchmarkPrimeAPRecords(b *testing.B) {
+	sieve := New(1000000)
+	for b.Loop() {
+		for range sieve.PrimeAPRecords(1000000) {
+		}
+	}
+}
+
+func ExampleSieve_PrimeAPRecords() {
+	for ap := range New(1000).PrimeAPRecords(3000) {
+		fmt.Println(ap.Length, ap.Start, ap.Step, ap.Last())
+	}
+	// Output:
+	// 1 2 0 2
+	// 2 2 1 3
+	// 3 3 2 7
+	// 4 5 6 23
+	// 5 5 6 29
+	// 6 7 30 157
+	// 7 7 150 907
+	// 8 199 210 1669
+	// 9 199 210 1879
+	// 10 199 210 2089
+}
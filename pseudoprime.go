@@ -0,0 +1,265 @@
+package sieve
+
+import (
+	"iter"
+	"math/big"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// IsCarmichael reports whether n is a Carmichael number, a composite n for which
+// b^(n-1) ≡ 1 (mod n) for every b coprime to n. By Korselt's criterion (1899) these are
+// the squarefree composites with p-1 dividing n-1 for every prime p dividing n. It
+// returns false if n is too big for the sieve to factor.
+func (sieve *Sieve) IsCarmichael(n int) bool {
+	if n < 3 || !sieve.reaches(n) { // too big for sieve?
+		return false
+	}
+	factors := sieve.FactorUnique(n)
+	if len(factors) < 2 {
+		return false // prime or prime power
+	}
+	for _, f := range factors {
+		if f.Count > 1 || (n-1)%(f.Factor-1) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// carmichaelWidth is the number of integers factored at a time.
+const carmichaelWidth = 1 << 16
+
+// Carmichael returns an iterator over the Carmichael numbers in [lo, hi]
+// (http://oeis.org/A002997). Rather than factoring each n, a factor-range sieve walks
+// the multiples of each prime p <= sqrt(hi), dividing p out of them and rejecting p
+// itself, the multiples of p², and those n for which p-1 does not divide n-1. What
+// remains of each n is 1 or a single large prime factor, which is checked in turn.
+func (sieve *Sieve) Carmichael(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		lo = max(lo, 3)
+		if hi < lo {
+			return
+		}
+		var primes []int
+		for p := range sieve.primes(3, int(isqrt64(uint64(hi)))) {
+			primes = append(primes, p) // Carmichael numbers are odd
+		}
+		rest := make([]int, carmichaelWidth)
+		for base := lo; base <= hi; base += carmichaelWidth {
+			n := min(carmichaelWidth, hi-base+1)
+			for i := range n {
+				rest[i] = base + i
+				if rest[i]&1 == 0 {
+					rest[i] = 0 // rejected
+				}
+			}
+			for _, p := range primes {
+				for i := (p - base%p) % p; i < n; i += p {
+					m := base + i
+					switch {
+					case rest[i] == 0:
+					case m == p || (m-1)%(p-1) != 0 || (m/p)%p == 0:
+						rest[i] = 0
+					default:
+						rest[i] /= p
+					}
+				}
+			}
+			for i := range n {
+				m, r := base+i, rest[i]
+				if r == 0 || r == m || r > 1 && (m-1)%(r-1) != 0 {
+					continue // rejected, prime, or large factor fails
+				}
+				if !yield(m) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// composites returns an iterator over the composite numbers in [lo, hi].
+func (sieve *Sieve) composites(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		n := max(lo, 4)
+		for p := range sieve.primes(n, hi) {
+			for ; n < p; n++ {
+				if !yield(n) {
+					return
+				}
+			}
+			n = p + 1
+		}
+		for ; n <= hi; n++ {
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// pseudoprimes returns an iterator over the composites n in [lo, hi] that pass test for
+// every base.
+func (sieve *Sieve) pseudoprimes(bases []int, lo, hi int, test func(n, b uint64) bool) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for n := range sieve.composites(lo, hi) {
+			passes := true
+			for _, b := range bases {
+				if passes = test(uint64(n), uint64(b)); !passes {
+					break
+				}
+			}
+			if passes && !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// FermatPseudoprimes returns an iterator over the composites n in [lo, hi] for which
+// b^(n-1) ≡ 1 (mod n) for each base b (http://oeis.org/A001567 for base 2).
+func (sieve *Sieve) FermatPseudoprimes(bases []int, lo, hi int) iter.Seq[int] {
+	return sieve.pseudoprimes(bases, lo, hi, func(n, b uint64) bool {
+		return modarith.PowMod(b, n-1, n) == 1
+	})
+}
+
+// EulerPseudoprimes returns an iterator over the odd composites n in [lo, hi] for which
+// b^((n-1)/2) ≡ (b/n) (mod n), where (b/n) is the Jacobi symbol, for each base b coprime
+// to n. These are the Euler-Jacobi pseudoprimes that fool the Solovay-Strassen test
+// (http://oeis.org/A047713 for base 2).
+func (sieve *Sieve) EulerPseudoprimes(bases []int, lo, hi int) iter.Seq[int] {
+	return sieve.pseudoprimes(bases, lo, hi, func(n, b uint64) bool {
+		if n&1 == 0 {
+			return false
+		}
+		switch modarith.PowMod(b, (n-1)/2, n) {
+		case 1:
+			return jacobi(b, n) == 1
+		case n - 1:
+			return jacobi(b, n) == -1
+		}
+		return false
+	})
+}
+
+// StrongPseudoprimes returns an iterator over the odd composites n in [lo, hi] that pass
+// the strong probable-prime test to each base (http://oeis.org/A001262 for base 2). No
+// composite below 3,215,031,751 is a strong pseudoprime to bases 2, 3, 5 and 7.
+func (sieve *Sieve) StrongPseudoprimes(bases []int, lo, hi int) iter.Seq[int] {
+	return sieve.pseudoprimes(bases, lo, hi, func(n, b uint64) bool {
+		return n&1 == 1 && b%n != 0 && strongProbable64(n, b)
+	})
+}
+
+// jacobi returns the Jacobi symbol (a/n) for odd n > 0.
+func jacobi(a, n uint64) int {
+	a %= n
+	j := 1
+	for a != 0 {
+		for a&1 == 0 {
+			a >>= 1
+			if r := n & 7; r == 3 || r == 5 {
+				j = -j
+			}
+		}
+		a, n = n, a
+		if a&3 == 3 && n&3 == 3 {
+			j = -j
+		}
+		a %= n
+	}
+	if n != 1 {
+		return 0
+	}
+	return j
+}
+
+// Chernick is a Carmichael number of the form found by Chernick (1939):
+// U_k(m) = (6m+1)(12m+1)(18m+1)(36m+1)···(9·2^(k-2)·m+1), which is a Carmichael number
+// when its k factors are all prime and, for k > 4, 2^(k-4) divides m.
+type Chernick struct {
+	M       int        // the parameter m
+	Factors []*big.Int // the k prime factors
+}
+
+// Product returns the Carmichael number.
+func (c Chernick) Product() *big.Int {
+	n := big.NewInt(1)
+	for _, f := range c.Factors {
+		n.Mul(n, f)
+	}
+	return n
+}
+
+// chernickReach bounds the primes used to sieve Chernick parameters.
+const chernickReach = 1 << 12
+
+// Chernick returns an iterator over the Carmichael numbers U_k(m) for m in [mLo, mHi],
+// for k >= 3. Each factor a·m+1 is divisible by a small prime q exactly when
+// m ≡ -1/a (mod q), so those classes are struck from a sieve over m before the factors
+// of the survivors are tested. The numbers have roughly k·log10(m) + 0.15k² + k/2
+// digits, the coefficients 6·12·∏9·2^i supplying the last two terms (about 20 digits
+// for k = 10), which makes this a simple way to build large Carmichael numbers for
+// testing.
+func (sieve *Sieve) Chernick(k, mLo, mHi int) iter.Seq[Chernick] {
+	return func(yield func(Chernick) bool) {
+		if k < 3 {
+			return
+		}
+		mLo = max(mLo, 1)
+		step := 1
+		if k > 4 {
+			step = 1 << (k - 4)
+		}
+		mLo = (mLo + step - 1) / step * step // first multiple of step
+
+		coefficients := []int{6, 12}
+		for i := 1; i <= k-2; i++ {
+			coefficients = append(coefficients, 9<<i)
+		}
+		type class struct{ q, a, r int } // q divides a·m+1 when m ≡ r (mod q)
+		var classes []class
+		for q := range sieve.primes(5, chernickReach) {
+			for _, a := range coefficients {
+				inverse, _ := modarith.InvMod(uint64(a), uint64(q))
+				classes = append(classes, class{q, a, q - int(inverse)})
+			}
+		}
+
+		struck := make([]bool, carmichaelWidth)
+		for lo := mLo; lo <= mHi; lo += carmichaelWidth * step {
+			n := min(carmichaelWidth, (mHi-lo)/step+1) // m = lo + i·step
+			clear(struck[:n])
+			for _, c := range classes {
+				// solve lo + i·step ≡ r (mod q) for i
+				x, _, _ := modarith.LinearCongruence(uint64(step), uint64(c.r-lo%c.q+c.q), uint64(c.q))
+				for i := int(x); i < n; i += c.q {
+					if c.a*(lo+i*step)+1 != c.q { // unless the factor is q itself
+						struck[i] = true
+					}
+				}
+			}
+			for i := range n {
+				if struck[i] {
+					continue
+				}
+				m := lo + i*step
+				c := Chernick{M: m}
+				for _, a := range coefficients {
+					f := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(m)))
+					f.Add(f, big.NewInt(1))
+					if !sieve.probablyPrime(f) {
+						c.Factors = nil
+						break
+					}
+					c.Factors = append(c.Factors, f)
+				}
+				if c.Factors != nil && !yield(c) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package sieve
+
+import (
+	"fmt"
+	"iter"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+var carmichaelTests = []struct {
+	lo, hi, count int
+}{
+	{0, 1000000, 43},
+	{0, 10000000, 105},
+	{1000000, 10000000, 62},
+	{561, 561, 1},
+	{562, 1104, 0},
+}
+
+func TestCarmichael(t *testing.T) {
+	for _, size := range []int{10, 10000} {
+		sieve := New(size)
+		for i, a := range carmichaelTests {
+			if c := count(sieve.Carmichael(a.lo, a.hi)); c != a.count {
+				t.Errorf("#%d, size %d, %d Carmichael numbers in [%d, %d]; want %d", i, size, c, a.lo, a.hi, a.count)
+			}
+		}
+	}
+	sieve := New(1000)
+	want := slices.Collect(sieve.Carmichael(0, 1000000))
+	var have []int
+	for n := range 1000001 {
+		if sieve.IsCarmichael(n) {
+			have = append(have, n)
+		}
+	}
+	if !slices.Equal(have, want) {
+		t.Errorf("IsCarmichael finds %v; Carmichael finds %v", have, want)
+	}
+}
+
+var pseudoprimeTests = []struct {
+	bases                 []int
+	fermat, euler, strong int
+	first                 []int // the first Fermat pseudoprimes
+}{
+	{[]int{2}, 245, 114, 46, []int{341, 561, 645, 1105, 1387, 1729, 1905, 2047}},
+	{[]int{3}, 246, 124, 73, []int{91, 121, 286, 671, 703, 949, 1105, 1541}},
+	{[]int{2, 3}, 66, 25, 0, []int{1105, 1729, 2465, 2701, 2821, 6601, 8911, 10585}},
+}
+
+func TestPseudoprimes(t *testing.T) {
+	sieve := New(1000)
+	for i, a := range pseudoprimeTests {
+		for j, test := range []struct {
+			seq  iter.Seq[int]
+			want int
+		}{
+			{sieve.FermatPseudoprimes(a.bases, 0, 1000000), a.fermat},
+			{sieve.EulerPseudoprimes(a.bases, 0, 1000000), a.euler},
+			{sieve.StrongPseudoprimes(a.bases, 0, 1000000), a.strong},
+		} {
+			if c := count(test.seq); c != test.want {
+				t.Errorf("#%d.%d, bases %v, %d pseudoprimes below 10^6; want %d", i, j, a.bases, c, test.want)
+			}
+		}
+		first := slices.Collect(Compose(sieve.FermatPseudoprimes(a.bases, 0, 1000000), Take(len(a.first))))
+		if !slices.Equal(first, a.first) {
+			t.Errorf("#%d, bases %v, pseudoprimes %v; want %v", i, a.bases, first, a.first)
+		}
+	}
+}
+
+var chernickTests = []struct {
+	k, mHi int
+	ms     []int
+	count  int
+}{
+	{3, 3000, []int{1, 6, 35, 45, 51, 55, 56, 100, 121, 195, 206, 216}, 68},
+	{4, 3000, []int{1, 45, 56, 121, 206, 255, 380, 506, 511, 710, 871, 1025}, 19},
+	{5, 3000, []int{380, 506}, 2},
+}
+
+func TestChernick(t *testing.T) {
+	sieve := New(1000)
+	for i, a := range chernickTests {
+		var ms []int
+		for c := range sieve.Chernick(a.k, 1, a.mHi) {
+			ms = append(ms, c.M)
+			n := c.Product()
+			m := new(big.Int).Sub(n, big.NewInt(1))
+			for _, f := range c.Factors { // Korselt's criterion
+				g := new(big.Int).Sub(f, big.NewInt(1))
+				if len(c.Factors) != a.k || g.Mod(m, g).Sign() != 0 {
+					t.Errorf("#%d, U_%d(%d) = %v is not a Carmichael number", i, a.k, c.M, n)
+				}
+			}
+		}
+		if len(ms) != a.count || !slices.Equal(ms[:len(a.ms)], a.ms) {
+			t.Errorf("#%d, U_%d(m) for m = %v; want %v... (%d)", i, a.k, ms, a.ms, a.count)
+		}
+	}
+}
+
+func BenchmarkCarmichael(b *testing.B) {
+	sieve := New(10000)
+	for b.Loop() {
+		for range sieve.Carmichael(0, 10000000) {
+		}
+	}
+}
+
+func ExampleSieve_Chernick() {
+	for c := range New(100).Chernick(6, 1, 200000) {
+		fmt.Println(c.M, c.Product())
+	}
+	// Output:
+	// 380 1457836374916028334162241
+	// 38460 1565532794336669121904650151411855681
+	// 40420 2109522448343063788124931486187997761
+}
@@ -0,0 +1,102 @@
+package sieve
+
+import "slices"
+
+// Prime counts in arithmetic progressions and Chebyshev's bias. In 1853 Chebyshev
+// remarked that primes 3 mod 4 seem to outnumber those 1 mod 4; the lead first changes
+// hands at 26861 (Leech, 1957) and infinitely often thereafter (Littlewood, 1914).
+
+// PrimePiAP returns π(x; q, a), the number of primes p <= x with p ≡ a (mod q), or 0
+// if q < 1.
+func (sieve *Sieve) PrimePiAP(x, q, a int) int {
+	counts := sieve.ResidueCounts(x, q)
+	if counts == nil {
+		return 0
+	}
+	return counts[(a%q+q)%q]
+}
+
+// ResidueCounts returns a table of π(x; q, a) for each residue 0 <= a < q, or nil if
+// q < 1.
+func (sieve *Sieve) ResidueCounts(x, q int) []int {
+	if q < 1 {
+		return nil
+	}
+	counts := make([]int, q)
+	for p := range sieve.primes(2, x) {
+		counts[p%q]++
+	}
+	return counts
+}
+
+// LeadChange records a prime at which the lead in a race changes hands.
+type LeadChange struct {
+	X      int `json:"x"`      // the prime at which the lead changes
+	Leader int `json:"leader"` // the residue that takes the lead
+}
+
+// Race follows a prime race among residue classes modulo Q. A class leads when its
+// count is strictly greater than every other; ties leave the lead where it was.
+type Race struct {
+	Q        int          // modulus
+	Residues []int        // residue classes in the race
+	Counts   []int        // count of primes seen in each class, parallel to Residues
+	Leader   int          // index in Residues of the leading class, or -1 before anyone leads
+	Changes  []LeadChange // every change of leader, beginning with the first
+	index    []int        // residue to index in Residues, or -1
+}
+
+// NewRace returns a race among the given residue classes modulo q, or nil if q < 1 or
+// a residue is repeated.
+func NewRace(q int, residues ...int) *Race {
+	if q < 1 {
+		return nil
+	}
+	race := &Race{Q: q, Residues: slices.Clone(residues), Counts: make([]int, len(residues)), Leader: -1, index: make([]int, q)}
+	for i := range race.index {
+		race.index[i] = -1
+	}
+	for i, a := range residues {
+		a = (a%q + q) % q
+		if race.index[a] >= 0 {
+			return nil
+		}
+		race.index[a] = i
+		race.Residues[i] = a
+	}
+	return race
+}
+
+// Add counts the prime p, which must exceed those already added, and notes any change
+// in the lead.
+func (race *Race) Add(p int) {
+	i := race.index[p%race.Q]
+	if i < 0 {
+		return
+	}
+	race.Counts[i]++
+	if i == race.Leader {
+		return
+	}
+	for j, c := range race.Counts {
+		if j != i && c >= race.Counts[i] {
+			return // i has not passed everyone
+		}
+	}
+	race.Leader = i
+	race.Changes = append(race.Changes, LeadChange{X: p, Leader: race.Residues[i]})
+}
+
+// Race runs a prime race among residue classes modulo q over the primes p <= hi,
+// returning nil if q < 1 or a residue is repeated. Races beyond the sieve are run in
+// segments. To continue a race, Add the primes after hi to the result.
+func (sieve *Sieve) Race(q int, residues []int, hi int) *Race {
+	race := NewRace(q, residues...)
+	if race == nil {
+		return nil
+	}
+	for p := range sieve.primes(2, hi) {
+		race.Add(p)
+	}
+	return race
+}
@@ -0,0 +1,90 @@
+package sieve
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+var residueTests = []struct {
+	x, q   int
+	counts []int
+}{
+	{1, 4, []int{0, 0, 0, 0}},
+	{100, 4, []int{0, 11, 1, 13}},
+	{1000000, 3, []int{1, 39231, 39266}},
+	{1000000, 4, []int{0, 39175, 1, 39322}},
+	{1000000, 8, []int{0, 19552, 1, 19653, 0, 19623, 0, 19669}},
+	{1000000, 10, []int{0, 19617, 1, 19665, 0, 1, 0, 19621, 0, 19593}},
+}
+
+func TestResidueCounts(t *testing.T) {
+	for _, size := range []int{1000, 1000000} {
+		sieve := New(size)
+		for i, a := range residueTests {
+			counts := sieve.ResidueCounts(a.x, a.q)
+			if !slices.Equal(counts, a.counts) {
+				t.Errorf("#%d, size %d, ResidueCounts(%d, %d) = %v; want %v", i, size, a.x, a.q, counts, a.counts)
+			}
+			for r, c := range a.counts {
+				if pi := sieve.PrimePiAP(a.x, a.q, r-a.q); pi != c {
+					t.Errorf("#%d, size %d, PrimePiAP(%d, %d, %d) = %d; want %d", i, size, a.x, a.q, r-a.q, pi, c)
+				}
+			}
+		}
+	}
+	if New(100).ResidueCounts(100, 0) != nil {
+		t.Errorf("ResidueCounts(100, 0) != nil")
+	}
+}
+
+var raceTests = []struct {
+	q        int
+	residues []int
+	changes  int
+	first    []LeadChange
+	last     LeadChange
+}{
+	{4, []int{1, 3}, 49, []LeadChange{{3, 3}, {26861, 1}, {26879, 3}, {616841, 1}}, LeadChange{633803, 3}},
+	{8, []int{1, 3, 5, 7}, 870, []LeadChange{{3, 3}, {37, 5}, {83, 3}, {197, 5}}, LeadChange{987991, 7}},
+	{3, []int{1, 2}, 1, []LeadChange{{2, 2}}, LeadChange{2, 2}},
+}
+
+func TestRace(t *testing.T) {
+	sieve := New(1000000)
+	for i, a := range raceTests {
+		race := sieve.Race(a.q, a.residues, 1000000)
+		if len(race.Changes) != a.changes {
+			t.Errorf("#%d, mod %d, %d lead changes; want %d", i, a.q, len(race.Changes), a.changes)
+			continue
+		}
+		if !slices.Equal(race.Changes[:len(a.first)], a.first) || race.Changes[a.changes-1] != a.last {
+			t.Errorf("#%d, mod %d, lead changes %v...%v; want %v...%v", i, a.q,
+				race.Changes[:len(a.first)], race.Changes[a.changes-1], a.first, a.last)
+		}
+	}
+	if NewRace(4, 1, 5) != nil || NewRace(0, 1) != nil {
+		t.Errorf("NewRace accepted invalid classes")
+	}
+	for _, residues := range [][]int{{-1, 5}, {-1, 3}} {
+		saved := slices.Clone(residues)
+		NewRace(4, residues...)
+		if !slices.Equal(residues, saved) {
+			t.Errorf("NewRace changed its residues %v to %v", saved, residues)
+		}
+	}
+}
+
+func BenchmarkRace(b *testing.B) {
+	sieve := New(1000000)
+	for b.Loop() {
+		sieve.Race(4, []int{1, 3}, 1000000)
+	}
+}
+
+func ExampleSieve_Race() {
+	race := New(1000).Race(4, []int{3, 1}, 30000)
+	fmt.Println(race.Counts, race.Changes)
+	// Output:
+	// [1633 1611] [{3 3} {26861 1} {26879 3}]
+}
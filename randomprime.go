@@ -0,0 +1,131 @@
+package sieve
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// NextPrimeOptions adjusts the search of NextPrimeBig.
+type NextPrimeOptions struct {
+	Rounds int // Miller-Rabin rounds with pseudorandom bases, as for big.Int.ProbablyPrime
+	Reach  int // largest sieve prime used to reject candidates; zero sieves with none
+}
+
+// DefaultNextPrimeOptions are used when NextPrimeBig is given nil options. Twenty rounds
+// bound the error below 4^-20 even for adversarial input, and ProbablyPrime adds a
+// Baillie-PSW test.
+var DefaultNextPrimeOptions = NextPrimeOptions{Rounds: 20, Reach: 1 << 16}
+
+// bigWindow is the number of odd candidates sieved at a time by NextPrimeBig.
+const bigWindow = 1 << 12
+
+// NextPrimeBig returns the smallest probable prime greater than start. Below 2^64 the
+// answer comes from NextPrime and is exact. Beyond, as in OpenSSL, the residues of the
+// first candidate modulo each odd prime of the sieve up to opts.Reach are computed once.
+// Windows of candidates are then sieved with them, striking every candidate that a small
+// prime divides, and the residues advanced from window to window, so that no big number
+// is divided after the start. Each survivor is put to opts.Rounds of Miller-Rabin and a
+// Baillie-PSW test; with zero rounds, or fewer, only the Baillie-PSW test is made.
+func (sieve *Sieve) NextPrimeBig(start *big.Int, opts *NextPrimeOptions) *big.Int {
+	if opts == nil {
+		opts = &DefaultNextPrimeOptions
+	}
+	if start.Sign() < 0 {
+		return big.NewInt(2)
+	}
+	if start.IsUint64() && start.Uint64() < maxPrime64 {
+		return new(big.Int).SetUint64(sieve.NextPrime(start.Uint64()))
+	}
+
+	n := new(big.Int).Add(start, bigOne)
+	n.SetBit(n, 0, 1) // first odd candidate > start
+	var p *big.Int
+	sieve.sieveBig(n, opts.Reach, [][2]uint64{{1, 0}}, func(j int) bool {
+		p = new(big.Int).Add(n, big.NewInt(int64(2*j)))
+		return !p.ProbablyPrime(max(opts.Rounds, 0))
+	})
+	return p
+}
+
+// sieveBig calls visit for each j >= 0, in order, for which no odd prime of the sieve up
+// to reach divides any of the forms a·(n+2j)+b, stopping when visit returns false. The
+// residues of n modulo the primes are computed once; then windows of j are sieved, for
+// p divides a·(n+2j)+b when j ≡ -(a·n+b)/2a (mod p), and the residues advanced by the
+// width of a window. The primes must all be smaller than the values of the forms.
+func (sieve *Sieve) sieveBig(n *big.Int, reach int, forms [][2]uint64, visit func(j int) bool) {
+	type class struct {
+		p, r uint64   // prime and residue of n at the window
+		inv  []uint64 // 1/2a mod p for each form, or 0 if p divides 2a
+	}
+	var classes []class
+	var m big.Int
+	for p := 3; p <= min(sieve.size, reach); p += 2 {
+		if sieve.bit(p) != 0 {
+			continue
+		}
+		c := class{p: uint64(p), r: m.Mod(n, m.SetUint64(uint64(p))).Uint64()}
+		for _, f := range forms {
+			if a := 2 * f[0] % c.p; a != 0 {
+				inv, _ := modarith.InvMod(a, c.p)
+				c.inv = append(c.inv, inv)
+			} else {
+				c.inv = append(c.inv, 0)
+			}
+		}
+		classes = append(classes, c)
+	}
+
+	struck := make([]bool, bigWindow)
+	for base := 0; ; base += bigWindow {
+		clear(struck)
+		for i := range classes {
+			c := &classes[i]
+			for k, f := range forms {
+				if c.inv[k] == 0 {
+					continue
+				}
+				v := (f[0]%c.p*c.r + f[1]) % c.p // a·n+b mod p
+				for j := (c.p - v) % c.p * c.inv[k] % c.p; j < bigWindow; j += c.p {
+					struck[j] = true
+				}
+			}
+			c.r = (c.r + 2*bigWindow) % c.p
+		}
+		for j, composite := range struck {
+			if !composite && !visit(base+j) {
+				return
+			}
+		}
+	}
+}
+
+// RandomPrime returns a probable prime of exactly the given number of bits, reading
+// randomness from rand (which should be crypto/rand.Reader for keys). As with
+// crypto/rand.Prime, the top two bits are set, so that the product of two such primes
+// has exactly twice as many bits. A random odd start is chosen and NextPrimeBig searches
+// upward from it, starting again should it run past the bit length. Primes that follow
+// long gaps are somewhat more likely to be chosen, a bias of no practical concern.
+func (sieve *Sieve) RandomPrime(bits int, rand io.Reader) (*big.Int, error) {
+	if bits < 2 {
+		return nil, errors.New("sieve: prime size must be at least 2 bits")
+	}
+	b := make([]byte, (bits+7)/8)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		b[0] &= byte(1<<((bits-1)%8+1) - 1) // clear bits above the size
+		start := new(big.Int).SetBytes(b)
+		start.SetBit(start, bits-1, 1)
+		if bits > 2 {
+			start.SetBit(start, bits-2, 1)
+		}
+		start.Sub(start, bigOne) // so that start itself is a candidate
+		if p := sieve.NextPrimeBig(start, nil); p.BitLen() == bits {
+			return p, nil
+		}
+	}
+}
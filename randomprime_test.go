@@ -0,0 +1,79 @@
+package sieve
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// nextProbablePrime is the naive search that NextPrimeBig accelerates.
+func nextProbablePrime(start *big.Int) *big.Int {
+	n := new(big.Int).Add(start, bigOne)
+	for !n.ProbablyPrime(20) {
+		n.Add(n, bigOne)
+	}
+	return n
+}
+
+func TestNextPrimeBig(t *testing.T) {
+	sieve := New(1 << 16)
+	r := rand.New(rand.NewSource(1))
+	var starts []*big.Int
+	for _, bits := range []uint{64, 65, 100, 127, 128, 256, 521} {
+		power := new(big.Int).Lsh(bigOne, bits)
+		starts = append(starts, power, new(big.Int).Sub(power, bigOne), new(big.Int).Rand(r, power))
+	}
+	for i, start := range starts {
+		want := nextProbablePrime(start)
+		for _, opts := range []*NextPrimeOptions{nil, {Rounds: 1, Reach: 100}, {Rounds: 0, Reach: 0}, {Rounds: -1, Reach: 100}} {
+			if p := sieve.NextPrimeBig(start, opts); p.Cmp(want) != 0 {
+				t.Errorf("#%d, NextPrimeBig(%v, %v) = %v; want %v", i, start, opts, p, want)
+			}
+		}
+	}
+	for i, n := range []uint64{0, 1, 2, 1000, 1 << 40, maxPrime64 - 1} {
+		if p := sieve.NextPrimeBig(new(big.Int).SetUint64(n), nil); p.Uint64() != sieve.NextPrime(n) {
+			t.Errorf("#%d, NextPrimeBig(%d) = %v; want %d", i, n, p, sieve.NextPrime(n))
+		}
+	}
+	if p := sieve.NextPrimeBig(new(big.Int).SetUint64(maxPrime64), nil); p.String() != "18446744073709551629" {
+		t.Errorf("NextPrimeBig(2^64-59) = %v; want 18446744073709551629", p)
+	}
+}
+
+func TestRandomPrime(t *testing.T) {
+	sieve := New(1 << 16)
+	r := rand.New(rand.NewSource(1))
+	for _, bits := range []int{2, 3, 4, 5, 8, 31, 32, 33, 63, 64, 65, 128, 512} {
+		for range 10 {
+			p, err := sieve.RandomPrime(bits, r)
+			if err != nil || p.BitLen() != bits || !p.ProbablyPrime(20) || bits > 2 && p.Bit(bits-2) != 1 {
+				t.Errorf("RandomPrime(%d) = %v, %v", bits, p, err)
+			}
+		}
+	}
+	if _, err := sieve.RandomPrime(1, r); err == nil {
+		t.Errorf("RandomPrime(1) succeeded")
+	}
+	if _, err := sieve.RandomPrime(128, bytes.NewReader(nil)); err == nil {
+		t.Errorf("RandomPrime with empty reader succeeded")
+	}
+}
+
+func BenchmarkRandomPrime1024(b *testing.B) {
+	sieve := New(1 << 16)
+	r := rand.New(rand.NewSource(1))
+	for b.Loop() {
+		sieve.RandomPrime(1024, r)
+	}
+}
+
+func ExampleSieve_NextPrimeBig() {
+	start := new(big.Int).Lsh(big.NewInt(1), 127) // 2^127
+	p := New(1<<16).NextPrimeBig(start, nil)
+	fmt.Println(new(big.Int).Sub(p, start))
+	// Output:
+	// 29
+}
@@ -0,0 +1,181 @@
+package sieve
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// The units mod n form a group of order φ(n) whose exponent, the least e with a^e ≡ 1
+// for every unit a, is Carmichael's λ(n) (1910). The order of each unit divides λ(n), so
+// it is found by dividing prime factors out of λ(n) for as long as the power stays 1.
+// The group is cyclic, and its generators are primitive roots, exactly when n is 1, 2, 4,
+// p^k or 2p^k for an odd prime p (Gauss, 1801), which is when λ(n) = φ(n).
+
+// CarmichaelLambda returns λ(n), the exponent of the group of units mod n
+// (http://oeis.org/A002322): the least common multiple of λ(p^k) over the prime powers
+// dividing n, where λ(p^k) = φ(p^k) except that λ(2^k) = 2^(k-2) for k >= 3. It returns
+// 0 if n < 1 or n is too big for the sieve to factor.
+func (sieve *Sieve) CarmichaelLambda(n int) int {
+	if n < 1 || !sieve.reaches(n) { // too big for sieve?
+		return 0
+	}
+	lambda := 1
+	for _, f := range sieve.FactorUnique(n) {
+		if f.Factor == 1 {
+			continue
+		}
+		l := f.Factor - 1
+		for range f.Count - 1 {
+			l *= f.Factor
+		}
+		if f.Factor == 2 && f.Count >= 3 {
+			l /= 2
+		}
+		lambda = lambda / gcd(lambda, l) * l
+	}
+	return lambda
+}
+
+// Order returns the multiplicative order of a mod n, the least k > 0 with a^k ≡ 1
+// (mod n), found by removing from λ(n) each prime factor q for which a^(k/q) is still 1.
+// It returns 0 if a and n are not coprime, n < 1, or n is too big for the sieve.
+func (sieve *Sieve) Order(a, n int) int {
+	if n < 1 || !sieve.reaches(n) {
+		return 0
+	}
+	if a %= n; a < 0 {
+		a += n
+	}
+	if gcd(a, n) != 1 {
+		return 0
+	}
+	k := sieve.CarmichaelLambda(n)
+	A, N := uint64(a), uint64(n)
+	for _, f := range sieve.FactorUnique(k) {
+		for range f.Count {
+			if f.Factor == 1 || modarith.PowMod(A, uint64(k/f.Factor), N) != 1 {
+				break
+			}
+			k /= f.Factor
+		}
+	}
+	return k
+}
+
+// cyclic reports whether the units mod n have a primitive root: n is 1, 2, 4, p^k or
+// 2p^k for an odd prime p.
+func (sieve *Sieve) cyclic(n int) bool {
+	if n <= 4 {
+		return n >= 1
+	}
+	if n%2 == 0 {
+		n /= 2
+	}
+	f := sieve.FactorUnique(n)
+	return len(f) == 1 && f[0].Factor > 2
+}
+
+// IsPrimitiveRoot reports whether g generates the units mod n, that is, whether g has
+// order φ(n): g must be coprime to n, and g^(φ(n)/q) ≢ 1 for each prime q dividing φ(n).
+// It returns false if n < 1 or n is too big for the sieve.
+func (sieve *Sieve) IsPrimitiveRoot(g, n int) bool {
+	if n < 1 || !sieve.reaches(n) || !sieve.cyclic(n) {
+		return false
+	}
+	if g %= n; g < 0 {
+		g += n
+	}
+	if gcd(g, n) != 1 {
+		return false
+	}
+	phi := sieve.Totient(n)
+	return sieve.generates(uint64(g), uint64(n), phi, sieve.FactorUnique(phi))
+}
+
+// generates is the test of IsPrimitiveRoot with the factors of phi = φ(n) in hand.
+func (sieve *Sieve) generates(g, n uint64, phi int, factors []Unique) bool {
+	for _, f := range factors {
+		if f.Factor > 1 && modarith.PowMod(g, uint64(phi/f.Factor), n) == 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// PrimitiveRoot returns the least primitive root of n, or 0 if there is none, as
+// http://oeis.org/A046145 does: 1 for n = 2, and 0 for n = 1, for n not of the form 4,
+// p^k or 2p^k, and for n too big for the sieve.
+func (sieve *Sieve) PrimitiveRoot(n int) int {
+	if n < 2 || !sieve.reaches(n) || !sieve.cyclic(n) {
+		return 0
+	}
+	phi := sieve.Totient(n)
+	factors := sieve.FactorUnique(phi)
+	for g := 1; ; g++ {
+		if gcd(g, n) == 1 && sieve.generates(uint64(g), uint64(n), phi, factors) {
+			return g
+		}
+	}
+}
+
+// PrimitiveRoots returns all primitive roots of n in ascending order, or nil if it has
+// none (see PrimitiveRoot). Given the least, g, they are the φ(φ(n)) powers g^k mod n with
+// k coprime to φ(n).
+func (sieve *Sieve) PrimitiveRoots(n int) []int {
+	g := sieve.PrimitiveRoot(n)
+	if g == 0 {
+		return nil
+	}
+	phi := sieve.Totient(n)
+	var roots []int
+	x, G, N := uint64(1), uint64(g), uint64(n)
+	for k := 1; k <= phi; k++ {
+		x = modarith.MulMod(x, G, N)
+		if gcd(k, phi) == 1 {
+			roots = append(roots, int(x))
+		}
+	}
+	slices.Sort(roots)
+	return roots
+}
+
+// FullReptendPrimes returns an iterator over the primes p in [lo, hi] for which the base
+// is a primitive root, so that 1/p written in that base repeats with the longest possible
+// period, p-1 digits (http://oeis.org/A001913 for base 10: 7, 17, 19, 23, 29, 47, ...).
+// Only p-1 is factored for each prime. The base must be at least 2, and primes beyond
+// Size()², whose p-1 the sieve cannot factor, are silently skipped.
+func (sieve *Sieve) FullReptendPrimes(base, lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if base < 2 {
+			return
+		}
+		for p := range sieve.primes(lo, min(hi, sieve.reach())) {
+			if base%p != 0 && sieve.generates(uint64(base%p), uint64(p), p-1, sieve.FactorUnique(p-1)) && !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+// DecimalPeriod returns the length of the repeating part of the decimal expansion of
+// 1/n (http://oeis.org/A051626), the order of 10 mod n once its factors 2 and 5 are
+// removed, or 0 if the expansion terminates, as it does when no other factors remain.
+// For a prime p other than 2 and 5 the period divides p-1. It also returns 0 for n < 1
+// or n too big for the sieve.
+func (sieve *Sieve) DecimalPeriod(n int) int {
+	if n < 1 {
+		return 0
+	}
+	for n%2 == 0 {
+		n /= 2
+	}
+	for n%5 == 0 {
+		n /= 5
+	}
+	if n == 1 {
+		return 0
+	}
+	return sieve.Order(10, n)
+}
@@ -0,0 +1,167 @@
+package sieve
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCarmichaelLambda(t *testing.T) {
+	sieve := New(10000)
+	want := []int{1, 1, 2, 2, 4, 2, 6, 2, 6, 4, 10, 2, 12, 6, 4, 4, 16, 6, 18, 4, 6, 10, 22, 2, 20, 12, 18, 6, 28, 4, 30, 8, 10, 16, 12, 6, 36, 18, 12, 4}
+	for n := 1; n <= len(want); n++ {
+		if v := sieve.CarmichaelLambda(n); v != want[n-1] {
+			t.Errorf("CarmichaelLambda(%d) = %d; want %d", n, v, want[n-1])
+		}
+	}
+	for i, a := range []struct{ n, lambda int }{
+		{0, 0}, {-5, 0}, {1 << 20, 1 << 18}, {9973 * 9967, 16563492}, {10001 * 10001, 0},
+	} {
+		if v := sieve.CarmichaelLambda(a.n); v != a.lambda {
+			t.Errorf("#%d, CarmichaelLambda(%d) = %d; want %d", i, a.n, v, a.lambda)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	sieve := New(10000)
+	for i, a := range []struct{ a, n, order int }{
+		{2, 7, 3}, {3, 7, 6}, {-1, 7, 2}, {6, 7, 2}, {1, 7, 1}, {0, 7, 0}, {4, 6, 0}, {5, 1, 1},
+		{10, 9973 * 9967, 2760582}, {2, 9973 * 9967, 5521164}, {3, 1000003, 333334},
+		{7, 1 << 20, 131072}, {5, 531441, 354294}, {2, 0, 0}, {2, 10001 * 10001, 0},
+	} {
+		if v := sieve.Order(a.a, a.n); v != a.order {
+			t.Errorf("#%d, Order(%d, %d) = %d; want %d", i, a.a, a.n, v, a.order)
+		}
+	}
+
+	// the order is the least k with a^k ≡ 1
+	for n := 1; n < 200; n++ {
+		for a := range n {
+			k, x := 0, 1%n
+			if gcd(a, n) == 1 {
+				for k, x = 1, a%n; x != 1%n; k++ {
+					x = x * a % n
+				}
+			}
+			if v := sieve.Order(a, n); v != k {
+				t.Errorf("Order(%d, %d) = %d; want %d", a, n, v, k)
+			}
+		}
+	}
+}
+
+func TestPrimitiveRoot(t *testing.T) {
+	sieve := New(10000)
+	want := []int{0, 1, 2, 3, 2, 5, 3, 0, 2, 3, 2, 0, 2, 3, 0, 0, 3, 5, 2, 0, 0, 7, 5, 0, 2, 7, 2, 0, 2, 0, 3, 0, 0, 3, 0, 0, 2, 3, 0, 0}
+	for n := 1; n <= len(want); n++ {
+		if g := sieve.PrimitiveRoot(n); g != want[n-1] {
+			t.Errorf("PrimitiveRoot(%d) = %d; want %d", n, g, want[n-1])
+		}
+	}
+	for i, a := range []struct {
+		n     int
+		roots string
+	}{
+		{54, "[5 11 23 29 41 47]"},
+		{25, "[2 3 8 12 13 17 22 23]"},
+		{2, "[1]"},
+		{8, "[]"},
+		{1, "[]"},
+	} {
+		if r := fmt.Sprint(sieve.PrimitiveRoots(a.n)); r != a.roots {
+			t.Errorf("#%d, PrimitiveRoots(%d) = %s; want %s", i, a.n, r, a.roots)
+		}
+	}
+
+	// IsPrimitiveRoot agrees with Order and Totient, and PrimitiveRoots lists those found
+	for n := 2; n < 300; n++ {
+		var roots []int
+		for g := -1; g < n; g++ {
+			is := sieve.IsPrimitiveRoot(g, n)
+			if want := sieve.Order(g, n) == sieve.Totient(n); is != want {
+				t.Errorf("IsPrimitiveRoot(%d, %d) = %v; want %v", g, n, is, want)
+			}
+			if is && g > 0 {
+				roots = append(roots, g)
+			}
+		}
+		if r := sieve.PrimitiveRoots(n); !slices.Equal(r, roots) {
+			t.Errorf("PrimitiveRoots(%d) = %v; want %v", n, r, roots)
+		}
+	}
+	if sieve.IsPrimitiveRoot(2, 0) || sieve.IsPrimitiveRoot(3, 10007*10009) || sieve.PrimitiveRoot(10007*10009) != 0 {
+		t.Errorf("primitive roots beyond the sieve")
+	}
+}
+
+func TestFullReptendPrimes(t *testing.T) {
+	sieve := New(10000)
+	for i, a := range []struct {
+		base, lo, hi int
+		primes       string
+	}{
+		{10, 0, 200, "[7 17 19 23 29 47 59 61 97 109 113 131 149 167 179 181 193]"},
+		{2, 0, 100, "[3 5 11 13 19 29 37 53 59 61 67 83]"},
+		{10, 100, 150, "[109 113 131 149]"},
+		{1, 0, 100, "[]"},
+	} {
+		if p := fmt.Sprint(slices.Collect(sieve.FullReptendPrimes(a.base, a.lo, a.hi))); p != a.primes {
+			t.Errorf("#%d, FullReptendPrimes(%d, %d, %d) = %s; want %s", i, a.base, a.lo, a.hi, p, a.primes)
+		}
+	}
+	if p := fmt.Sprint(slices.Collect(New(10).FullReptendPrimes(10, 50, 200))); p != "[59 61 97]" {
+		t.Errorf("FullReptendPrimes beyond the reach of New(10) = %s", p)
+	}
+	for p := range sieve.FullReptendPrimes(10, 0, 10000) {
+		if sieve.DecimalPeriod(p) != p-1 {
+			t.Errorf("full reptend prime %d has period %d", p, sieve.DecimalPeriod(p))
+		}
+	}
+}
+
+func TestDecimalPeriod(t *testing.T) {
+	sieve := New(10000)
+	want := []int{0, 0, 1, 0, 0, 1, 6, 0, 1, 0, 2, 1, 6, 6, 1, 0, 16, 1, 18, 0, 6, 2, 22, 1, 0, 6, 3, 6, 28, 1, 15, 0, 2, 16, 6, 1, 3, 18, 6, 0}
+	for n := 1; n <= len(want); n++ {
+		if v := sieve.DecimalPeriod(n); v != want[n-1] {
+			t.Errorf("DecimalPeriod(%d) = %d; want %d", n, v, want[n-1])
+		}
+	}
+	for i, a := range []struct{ n, period int }{
+		{0, 0}, {1000003, 166667}, {9973 * 9967, 2760582}, {1 << 40, 0}, {10007 * 10009, 0},
+	} {
+		if v := sieve.DecimalPeriod(a.n); v != a.period {
+			t.Errorf("#%d, DecimalPeriod(%d) = %d; want %d", i, a.n, v, a.period)
+		}
+	}
+}
+
+func BenchmarkPrimitiveRoot(b *testing.B) {
+	sieve := New(1 << 16)
+	for b.Loop() {
+		for n := 1; n < 1000; n++ {
+			sieve.PrimitiveRoot(n)
+		}
+	}
+}
+
+func BenchmarkOrder(b *testing.B) {
+	sieve := New(1 << 16)
+	for b.Loop() {
+		sieve.Order(10, 9973*9967)
+	}
+}
+
+func ExampleSieve_DecimalPeriod() {
+	sieve := New(1000)
+	fmt.Println(sieve.DecimalPeriod(7), sieve.DecimalPeriod(13), sieve.DecimalPeriod(40))
+	// 1/7 = 0.(142857), 1/13 = 0.(076923), 1/40 = 0.025
+	// Output: 6 6 0
+}
+
+func ExampleSieve_PrimitiveRoots() {
+	sieve := New(1000)
+	fmt.Println(sieve.PrimitiveRoot(41), sieve.PrimitiveRoots(18))
+	// Output: 6 [5 11]
+}
@@ -0,0 +1,526 @@
+// Package rsaweak checks RSA public keys for weaknesses that let them be factored from
+// the public key alone: small prime factors, primes so close that Fermat's method finds
+// them, a private exponent small enough for Wiener's attack, a prime p with p-1 smooth,
+// and primes shared between keys of a set. It works offline, with the primes of a sieve
+// and the package's factoring and batch GCD code.
+package rsaweak
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/MichaelTJones/sieve"
+)
+
+// Names of the checks, as they appear in findings.
+const (
+	CheckSmallFactor  = "small-factor"
+	CheckFermat       = "fermat"
+	CheckWiener       = "wiener"
+	CheckPM1          = "p-1"
+	CheckSharedFactor = "shared-factor"
+	CheckSquare       = "square"
+)
+
+// Key is an RSA public key. Unlike rsa.PublicKey, it holds exponents of any size, as
+// keys vulnerable to Wiener's attack have exponents about as large as their moduli.
+type Key struct {
+	N, E *big.Int
+}
+
+// KeyOf converts an rsa.PublicKey.
+func KeyOf(pub *rsa.PublicKey) *Key {
+	return &Key{N: pub.N, E: big.NewInt(int64(pub.E))}
+}
+
+// Finding is a weakness found in a key. P and Q are the factors recovered, the factor
+// shared first for CheckSharedFactor, and D the private exponent when Wiener's attack
+// finds it. Shared lists the other keys of the set, by index, with a factor in common.
+type Finding struct {
+	Check  string `json:"check"`
+	Detail string `json:"detail"`
+	P      string `json:"p,omitempty"`
+	Q      string `json:"q,omitempty"`
+	D      string `json:"d,omitempty"`
+	Shared []int  `json:"shared,omitempty"`
+}
+
+// KeyReport lists the findings for one key, identified by its position in the set, the
+// SHA-256 of its modulus, and its size.
+type KeyReport struct {
+	Index       int       `json:"index"`
+	Fingerprint string    `json:"fingerprint"`
+	Bits        int       `json:"bits"`
+	E           string    `json:"e"`
+	Findings    []Finding `json:"findings"`
+}
+
+// Weak reports whether any check found a weakness in the key.
+func (k *KeyReport) Weak() bool {
+	return len(k.Findings) > 0
+}
+
+// Report is the result of checking a set of keys.
+type Report struct {
+	Keys []KeyReport `json:"keys"`
+	Weak int         `json:"weak"` // number of keys with findings
+}
+
+// WriteJSON writes the report as an indented JSON object.
+func (r *Report) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(r)
+}
+
+// Options bounds the work of each check.
+type Options struct {
+	SmallBound       int // primes up to this bound are tried as factors
+	FermatIterations int // steps of Fermat's method, from ⌈√n⌉ upward
+	PM1Bound         int // stage one bound of Pollard's p-1 method
+}
+
+// DefaultOptions finds factors below 2^20, primes within about 2^9·n^(1/4) of each other,
+// and primes p with p-1 100000-smooth.
+var DefaultOptions = Options{SmallBound: 1 << 20, FermatIterations: 1 << 16, PM1Bound: 100000}
+
+// Checker runs the checks with a sieve sized for the options.
+type Checker struct {
+	opts    Options
+	sieve   *sieve.Sieve
+	product *big.Int // of the primes up to SmallBound
+}
+
+// New returns a Checker with the given options.
+func New(opts Options) *Checker {
+	c := &Checker{opts: opts, sieve: sieve.New(max(opts.SmallBound, opts.PM1Bound, 2))}
+	c.product = big.NewInt(1)
+	for p := range c.sieve.All() {
+		if p > opts.SmallBound {
+			break
+		}
+		c.product.Mul(c.product, big.NewInt(int64(p)))
+	}
+	return c
+}
+
+// Check runs every check on each key and the shared-factor check on the set.
+func (c *Checker) Check(keys []*Key) *Report {
+	r := &Report{Keys: make([]KeyReport, len(keys))}
+	moduli := make([]*big.Int, len(keys))
+	for i, k := range keys {
+		sum := sha256.Sum256(k.N.Bytes())
+		r.Keys[i] = KeyReport{Index: i, Fingerprint: hex.EncodeToString(sum[:]), Bits: k.N.BitLen(), E: k.E.String(), Findings: []Finding{}}
+		moduli[i] = k.N
+		for _, check := range []func(*Key) *Finding{c.SmallFactor, c.Fermat, c.Wiener, c.PM1} {
+			if f := check(k); f != nil {
+				r.Keys[i].Findings = append(r.Keys[i].Findings, *f)
+			}
+		}
+	}
+	for i, f := range SharedFactors(moduli) {
+		if f != nil {
+			r.Keys[i].Findings = append(r.Keys[i].Findings, *f)
+		}
+	}
+	for _, k := range r.Keys {
+		if k.Weak() {
+			r.Weak++
+		}
+	}
+	return r
+}
+
+// finding records the factorization n = p·q.
+func finding(check, detail string, n, p *big.Int) *Finding {
+	q := new(big.Int).Quo(n, p)
+	if p.Cmp(q) > 0 {
+		p, q = q, p
+	}
+	return &Finding{Check: check, Detail: detail, P: p.String(), Q: q.String()}
+}
+
+// SmallFactor finds the prime factors of the modulus up to SmallBound with one GCD
+// against the product of those primes, and factors that GCD with the sieve.
+func (c *Checker) SmallFactor(k *Key) *Finding {
+	g := new(big.Int).GCD(nil, nil, k.N, c.product)
+	if g.Cmp(big.NewInt(1)) == 0 {
+		return nil
+	}
+	f := c.sieve.FactorBig(g)
+	p := f[0].Factor
+	return finding(CheckSmallFactor, "modulus divisible by "+c.sieve.FactorStringBig(g), k.N, p)
+}
+
+// Fermat tries Fermat's method, seeking a with a²-n a square b², so n = (a-b)(a+b),
+// for a from ⌈√n⌉ through FermatIterations steps. It succeeds at once when the primes
+// agree in their top half, and within the steps when |p-q| is below about
+// √(8·FermatIterations)·n^(1/4). Only one b² in five is a square mod 64, and only those
+// are given the full square root. A modulus that is a perfect square, found at the first
+// step, is reported under CheckSquare rather than as two equal primes, as its root need
+// not be prime.
+func (c *Checker) Fermat(k *Key) *Finding {
+	n := k.N
+	a := new(big.Int).Sqrt(n)
+	var b2, b, t big.Int
+	if t.Mul(a, a).Cmp(n) < 0 {
+		a.Add(a, big.NewInt(1))
+	}
+	b2.Mul(a, a).Sub(&b2, n)
+	for i := range c.opts.FermatIterations {
+		if w := b2.Bits(); len(w) == 0 || square64[w[0]&63] {
+			if t.Mul(b.Sqrt(&b2), &b).Cmp(&b2) == 0 {
+				if b.Sign() == 0 {
+					return &Finding{Check: CheckSquare, Detail: fmt.Sprintf("modulus is a perfect square, of %v", a)}
+				}
+				p := new(big.Int).Sub(a, &b)
+				if p.Cmp(big.NewInt(1)) == 0 {
+					return nil // n = 1·n, and a has run to (n+1)/2
+				}
+				return finding(CheckFermat, fmt.Sprintf("primes differ by %v, found after %d steps", t.Lsh(&b, 1), i), n, p)
+			}
+		}
+		b2.Add(&b2, t.Lsh(a, 1)).Add(&b2, big.NewInt(1)) // (a+1)² - a² = 2a+1
+		a.Add(a, big.NewInt(1))
+	}
+	return nil
+}
+
+// square64 marks the squares mod 64.
+var square64 = func() (s [64]bool) {
+	for i := range 64 {
+		s[i*i%64] = true
+	}
+	return
+}()
+
+// Wiener tries Wiener's attack (1990): when d < n^(1/4)/3, d is the denominator of a
+// convergent k/d of the continued fraction of e/n. Each convergent gives a candidate
+// φ(n) = (e·d-1)/k, which is right when p and q, the roots of x² - (n-φ+1)x + n, are
+// integers.
+func (c *Checker) Wiener(k *Key) *Finding {
+	n, e := k.N, k.E
+	one := big.NewInt(1)
+	x, y := new(big.Int).Set(e), new(big.Int).Set(n) // the fraction x/y still to expand
+	h0, h1 := big.NewInt(0), big.NewInt(1)           // numerators
+	k0, k1 := big.NewInt(1), big.NewInt(0)           // denominators
+	var q, r, t, phi, s, disc, root big.Int
+	for y.Sign() != 0 {
+		q.QuoRem(x, y, &r)
+		x.Set(y)
+		y.Set(&r)
+		h0, h1 = h1, h0.Add(h0, t.Mul(&q, h1))
+		k0, k1 = k1, k0.Add(k0, t.Mul(&q, k1))
+		K, D := h1, k1 // the convergent K/D
+		if K.Sign() == 0 {
+			continue
+		}
+		phi.Mul(e, D).Sub(&phi, one)
+		if t.Mod(&phi, K).Sign() != 0 {
+			continue
+		}
+		phi.Quo(&phi, K)
+		s.Sub(n, &phi).Add(&s, one) // p + q
+		disc.Mul(&s, &s).Sub(&disc, t.Lsh(n, 2))
+		if disc.Sign() < 0 {
+			continue
+		}
+		root.Sqrt(&disc)
+		if t.Mul(&root, &root).Cmp(&disc) != 0 {
+			continue
+		}
+		p := new(big.Int).Sub(&s, &root)
+		p.Rsh(p, 1)
+		if p.Sign() <= 0 || p.Cmp(one) == 0 || new(big.Int).Mod(n, p).Sign() != 0 {
+			continue
+		}
+		f := finding(CheckWiener, fmt.Sprintf("private exponent has %d bits", D.BitLen()), n, p)
+		f.D = D.String()
+		return f
+	}
+	return nil
+}
+
+// PM1 tries Pollard's p-1 method with the sieve's primes up to PM1Bound, raising 2 to
+// each prime's largest power within the bound. It finds p when p-1 is that smooth. The
+// GCD is taken every few dozen primes; should it reach n, the last batch is replayed one
+// prime at a time to separate the factors.
+func (c *Checker) PM1(k *Key) *Finding {
+	const batch = 64
+	n := k.N
+	one := big.NewInt(1)
+	a, saved := big.NewInt(2), big.NewInt(2)
+	var e, g big.Int
+	var primes []int
+	power := func(p int) *big.Int {
+		q := p
+		for q <= c.opts.PM1Bound/p {
+			q *= p
+		}
+		return e.SetInt64(int64(q))
+	}
+	check := func() (f *Finding, stop bool) {
+		g.GCD(nil, nil, e.Sub(a, one), n)
+		if g.Cmp(n) == 0 { // both factors at once; replay
+			a.Set(saved)
+			for _, p := range primes {
+				a.Exp(a, power(p), n)
+				if g.GCD(nil, nil, e.Sub(a, one), n).Cmp(one) != 0 {
+					break
+				}
+			}
+		}
+		switch {
+		case g.Cmp(n) == 0:
+			return nil, true // both at the same prime; a stays 1 from here on
+		case g.Cmp(one) != 0:
+			return finding(CheckPM1, fmt.Sprintf("p-1 is %d-smooth", c.opts.PM1Bound), n, &g), true
+		}
+		saved.Set(a)
+		primes = primes[:0]
+		return nil, false
+	}
+	for p := range c.sieve.All() {
+		if p > c.opts.PM1Bound {
+			break
+		}
+		a.Exp(a, power(p), n)
+		primes = append(primes, p)
+		if len(primes) == batch {
+			if f, stop := check(); stop {
+				return f
+			}
+		}
+	}
+	f, _ := check()
+	return f
+}
+
+// SharedFactors finds the moduli that share a factor with another of the set, using
+// sieve.BatchGCD, and reports for each such modulus the factor shared, its cofactor, and
+// the others sharing them. It returns nil entries for the rest. The batch GCD of an RSA
+// modulus is its shared prime, but of other moduli may be any factor. Keys are matched
+// by these factors rather than by GCDs across the set. A modulus whose batch GCD is the
+// whole modulus, as when both its primes are shared, is split by a factor found in
+// another key or, failing that, by a GCD with another such modulus; and a modulus whose
+// factors match no other key's, as can happen for moduli not the product of two primes,
+// is matched by GCDs with the other flagged moduli. These are few.
+func SharedFactors(moduli []*big.Int) []*Finding {
+	result := make([]*Finding, len(moduli))
+	gcds := sieve.BatchGCD(moduli)
+	one := big.NewInt(1)
+	factors := make([][]*big.Int, len(moduli)) // a shared factor of each flagged modulus and its cofactor, once known
+	var known []*big.Int                       // every factor found from a batch GCD
+	var whole []int                            // flagged moduli whose batch GCD is the modulus
+	for i, d := range gcds {
+		switch {
+		case d.Cmp(one) == 0:
+		case d.Cmp(moduli[i]) == 0:
+			whole = append(whole, i)
+		default:
+			factors[i] = []*big.Int{d, new(big.Int).Quo(moduli[i], d)}
+			known = append(known, factors[i]...)
+		}
+	}
+	var g big.Int
+	for _, i := range whole {
+		n := moduli[i]
+		for _, p := range known {
+			if p.Cmp(n) < 0 && g.Mod(n, p).Sign() == 0 {
+				factors[i] = []*big.Int{p, new(big.Int).Quo(n, p)}
+				break
+			}
+		}
+		for _, j := range whole {
+			if factors[i] != nil {
+				break
+			}
+			if g.GCD(nil, nil, n, moduli[j]); g.Cmp(one) != 0 && g.Cmp(n) != 0 {
+				p := new(big.Int).Set(&g)
+				factors[i] = []*big.Int{p, new(big.Int).Quo(n, p)}
+			}
+		}
+	}
+
+	// flagged keys by modulus, to find repeats, and by each of their factors
+	byModulus := make(map[string][]int)
+	byFactor := make(map[string][]int)
+	for i, d := range gcds {
+		if d.Cmp(one) == 0 {
+			continue
+		}
+		byModulus[moduli[i].String()] = append(byModulus[moduli[i].String()], i)
+		for _, p := range factors[i] {
+			byFactor[p.String()] = append(byFactor[p.String()], i)
+		}
+	}
+	for i, d := range gcds {
+		if d.Cmp(one) == 0 {
+			continue
+		}
+		f := &Finding{Check: CheckSharedFactor}
+		var keys []int
+		for _, p := range append([]*big.Int{moduli[i]}, factors[i]...) {
+			keys = append(keys, byModulus[p.String()]...)
+			keys = append(keys, byFactor[p.String()]...)
+		}
+		slices.Sort(keys)
+		for _, j := range slices.Compact(keys) {
+			if j != i {
+				f.Shared = append(f.Shared, j)
+			}
+		}
+		if f.Shared == nil { // as for 2^40 beside 2 and 4
+			for j, e := range gcds {
+				if j != i && e.Cmp(one) != 0 && g.GCD(nil, nil, moduli[i], moduli[j]).Cmp(one) != 0 {
+					f.Shared = append(f.Shared, j)
+				}
+			}
+		}
+		switch {
+		case factors[i] != nil:
+			f.Detail = "factor shared with " + otherKeys(len(f.Shared))
+			f.P, f.Q = factors[i][0].String(), factors[i][1].String()
+		case len(byModulus[moduli[i].String()]) > 1:
+			f.Detail = "modulus repeated in the set"
+		default:
+			f.Detail = "modulus divides the product of " + otherKeys(len(f.Shared))
+		}
+		result[i] = f
+	}
+	return result
+}
+
+// otherKeys counts the other keys of a finding.
+func otherKeys(n int) string {
+	if n == 1 {
+		return "1 other key"
+	}
+	return fmt.Sprintf("%d other keys", n)
+}
+
+// ParseKeys reads RSA public keys from PEM data, which may hold any number of PUBLIC KEY
+// (PKIX), RSA PUBLIC KEY (PKCS #1) and CERTIFICATE blocks, or from a single key or
+// certificate in DER form. Other PEM blocks are skipped; keys of other types are errors.
+// The ASN.1 is decoded here rather than by crypto/x509, which rejects the large public
+// exponents that some weak keys have.
+func ParseKeys(data []byte) ([]*Key, error) {
+	var keys []*Key
+	rest := data
+	for n := 0; ; n++ { // n counts every PEM block, skipped or not
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		var k *Key
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			k, err = parsePKIX(block.Bytes)
+		case "RSA PUBLIC KEY":
+			k, err = parsePKCS1(block.Bytes)
+		case "CERTIFICATE":
+			k, err = parseCertificate(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rsaweak: %s block %d: %v", strings.ToLower(block.Type), n, err)
+		}
+		keys = append(keys, k)
+	}
+	if keys != nil || strings.Contains(string(data), "-----BEGIN") {
+		return keys, nil
+	}
+
+	// DER: try each form in turn
+	for _, parse := range []func([]byte) (*Key, error){parsePKIX, parsePKCS1, parseCertificate} {
+		if k, err := parse(data); err == nil {
+			return []*Key{k}, nil
+		}
+	}
+	return nil, errors.New("rsaweak: no PEM blocks, and not a DER RSA public key or certificate")
+}
+
+// oidRSA identifies rsaEncryption keys (RFC 8017).
+var oidRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+
+// publicKeyInfo is the SubjectPublicKeyInfo of RFC 5280.
+type publicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// certificate is as much of an X.509 certificate as locates its key.
+type certificate struct {
+	TBS struct {
+		Version    int `asn1:"optional,explicit,default:0,tag:0"`
+		Serial     *big.Int
+		Signature  pkix.AlgorithmIdentifier
+		Issuer     asn1.RawValue
+		Validity   asn1.RawValue
+		Subject    asn1.RawValue
+		PublicKey  publicKeyInfo
+		IssuerID   asn1.BitString `asn1:"optional,tag:1"`
+		SubjectID  asn1.BitString `asn1:"optional,tag:2"`
+		Extensions asn1.RawValue  `asn1:"optional,explicit,tag:3"`
+	}
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+}
+
+// parsePKCS1 decodes an RSAPublicKey, SEQUENCE { n INTEGER, e INTEGER }.
+func parsePKCS1(der []byte) (*Key, error) {
+	var k Key
+	if rest, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after key")
+	}
+	if k.N.Sign() <= 0 || k.E.Sign() <= 0 {
+		return nil, errors.New("modulus and exponent must be positive")
+	}
+	return &k, nil
+}
+
+// parsePKIX decodes a SubjectPublicKeyInfo holding an RSA key.
+func parsePKIX(der []byte) (*Key, error) {
+	var info publicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after key")
+	}
+	return info.key()
+}
+
+// key decodes the RSA key within a SubjectPublicKeyInfo.
+func (info *publicKeyInfo) key() (*Key, error) {
+	if !info.Algorithm.Algorithm.Equal(oidRSA) {
+		return nil, fmt.Errorf("algorithm %v is not RSA", info.Algorithm.Algorithm)
+	}
+	return parsePKCS1(info.PublicKey.RightAlign())
+}
+
+// parseCertificate decodes the RSA key of an X.509 certificate.
+func parseCertificate(der []byte) (*Key, error) {
+	var cert certificate
+	if rest, err := asn1.Unmarshal(der, &cert); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after certificate")
+	}
+	return cert.TBS.PublicKey.key()
+}
@@ -0,0 +1,331 @@
+package rsaweak
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MichaelTJones/sieve"
+)
+
+// weakKeys builds one key for each check, and a strong one, deterministically.
+type weakKeys struct {
+	strong, small, fermat, wiener, pm1 *Key
+	shared                             []*Key
+}
+
+func newWeakKeys(t testing.TB) *weakKeys {
+	s := sieve.New(1 << 17)
+	r := sieve.NewSeededReader([]byte("rsaweak"))
+	prime := func(bits int) *big.Int {
+		p, err := s.RandomPrime(bits, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	mul := func(a, b *big.Int) *big.Int { return new(big.Int).Mul(a, b) }
+	e := big.NewInt(65537)
+	w := new(weakKeys)
+
+	p, q := prime(512), prime(512)
+	w.strong = &Key{mul(p, q), e}
+	w.small = &Key{mul(big.NewInt(1000003), prime(1000)), e}
+
+	near := new(big.Int).Lsh(big.NewInt(1), 200)
+	p = prime(512)
+	w.fermat = &Key{mul(p, s.NextPrimeBig(near.Add(near, p), nil)), e}
+
+	// d is a prime of 200 bits, well below n^(1/4)/3
+	p, q = prime(512), prime(512)
+	d := s.NextPrimeBig(new(big.Int).Lsh(big.NewInt(1), 199), nil)
+	phi := mul(p.Sub(p, big.NewInt(1)), q.Sub(q, big.NewInt(1)))
+	p.Add(p, big.NewInt(1))
+	q.Add(q, big.NewInt(1))
+	w.wiener = &Key{mul(p, q), new(big.Int).ModInverse(d, phi)}
+
+	// p-1 = 2·(distinct primes below 1000)·r for a prime r below 100000
+	m := big.NewInt(2)
+	for p := range s.All() {
+		if p > 2 && m.BitLen() < 480 {
+			m.Mul(m, big.NewInt(int64(p)))
+		}
+		if p > 1000 {
+			if c := mul(m, big.NewInt(int64(p))); c.Add(c, big.NewInt(1)).ProbablyPrime(20) {
+				w.pm1 = &Key{mul(c, prime(512)), e}
+				break
+			}
+		}
+	}
+	if w.pm1 == nil {
+		t.Fatal("no p-1 smooth prime")
+	}
+
+	a, b, c := prime(512), prime(512), prime(512)
+	w.shared = []*Key{{mul(a, b), e}, {mul(c, prime(512)), e}, {mul(a, c), e}}
+	return w
+}
+
+func TestChecks(t *testing.T) {
+	w := newWeakKeys(t)
+	c := New(DefaultOptions)
+	for i, a := range []struct {
+		key   *Key
+		check string
+		f     func(*Key) *Finding
+	}{
+		{w.small, CheckSmallFactor, c.SmallFactor},
+		{w.fermat, CheckFermat, c.Fermat},
+		{w.wiener, CheckWiener, c.Wiener},
+		{w.pm1, CheckPM1, c.PM1},
+	} {
+		for _, f := range []func(*Key) *Finding{c.SmallFactor, c.Fermat, c.Wiener, c.PM1} {
+			if g := f(w.strong); g != nil {
+				t.Errorf("#%d, strong key has finding %+v", i, g)
+			}
+		}
+		f := a.f(a.key)
+		if f == nil {
+			t.Errorf("#%d, %s check missed", i, a.check)
+			continue
+		}
+		P, _ := new(big.Int).SetString(f.P, 10)
+		Q, _ := new(big.Int).SetString(f.Q, 10)
+		if f.Check != a.check || P == nil || Q == nil || P.Cmp(Q) > 0 || new(big.Int).Mul(P, Q).Cmp(a.key.N) != 0 {
+			t.Errorf("#%d, %s finding %+v does not factor the modulus", i, a.check, f)
+		}
+	}
+	for _, n := range []*big.Int{new(big.Int).Mul(w.strong.N, w.strong.N), new(big.Int).Lsh(big.NewInt(1), 40)} {
+		if f := c.Fermat(&Key{n, big.NewInt(65537)}); f == nil || f.Check != CheckSquare || f.P != "" || f.Q != "" {
+			t.Errorf("Fermat(%v) = %+v; want a square", n, f)
+		}
+	}
+	if f := c.Wiener(w.wiener); f == nil || f.D == "" {
+		t.Errorf("Wiener finding %+v has no private exponent", f)
+	} else if D, _ := new(big.Int).SetString(f.D, 10); D.BitLen() != 200 {
+		t.Errorf("Wiener found d = %v", D)
+	}
+}
+
+func TestSharedFactors(t *testing.T) {
+	w := newWeakKeys(t)
+	moduli := []*big.Int{w.shared[0].N, w.shared[1].N, w.shared[2].N, w.strong.N, w.strong.N}
+	findings := SharedFactors(moduli)
+	for i, want := range [][]int{{2}, {2}, {0, 1}, {4}, {3}} {
+		f := findings[i]
+		if f == nil || f.Check != CheckSharedFactor || len(f.Shared) != len(want) {
+			t.Errorf("#%d, finding %+v; want shared with %v", i, f, want)
+			continue
+		}
+		for j := range want {
+			if f.Shared[j] != want[j] {
+				t.Errorf("#%d, shared with %v; want %v", i, f.Shared, want)
+			}
+		}
+		if i < 3 {
+			P, _ := new(big.Int).SetString(f.P, 10)
+			if P == nil || new(big.Int).Mod(moduli[i], P).Sign() != 0 || P.BitLen() != 512 {
+				t.Errorf("#%d, shared factor %s", i, f.P)
+			}
+		}
+	}
+
+	// ab, bc, ca: each batch GCD is the whole modulus, and no factor is known from another
+	a, b, c := w.shared[0].N, w.shared[1].N, w.strong.N
+	ab, bc, ca := new(big.Int).Mul(a, b), new(big.Int).Mul(b, c), new(big.Int).Mul(c, a)
+	for i, f := range SharedFactors([]*big.Int{ab, bc, ca}) {
+		if f == nil || f.P == "" || len(f.Shared) != 2 {
+			t.Errorf("triangle #%d, finding %+v", i, f)
+		}
+	}
+
+	// p·q·r beside p·q·s share the factor p·q, which is not prime
+	p, q := new(big.Int).Mul(w.shared[0].N, w.shared[1].N), w.strong.N
+	pq := new(big.Int).Mul(p, q)
+	r, s := big.NewInt(1000003), big.NewInt(1000033)
+	for i, f := range SharedFactors([]*big.Int{new(big.Int).Mul(pq, r), new(big.Int).Mul(pq, s)}) {
+		if f == nil || f.P != pq.String() || f.Detail != "factor shared with 1 other key" || len(f.Shared) != 1 {
+			t.Errorf("three primes #%d, finding %+v", i, f)
+		}
+	}
+
+	// 2^40 shares 8 with no other key, yet shares factors with both; 2 is prime, not repeated
+	findings = SharedFactors([]*big.Int{new(big.Int).Lsh(big.NewInt(1), 40), big.NewInt(2), big.NewInt(4)})
+	for i, f := range findings {
+		if f == nil || len(f.Shared) == 0 {
+			t.Errorf("powers of 2 #%d, finding %+v", i, f)
+		}
+	}
+	if f := findings[0]; f == nil || f.P != "8" || f.Detail != "factor shared with 2 other keys" {
+		t.Errorf("2^40, finding %+v", f)
+	}
+	if f := findings[1]; f == nil || !strings.HasPrefix(f.Detail, "modulus divides the product of") {
+		t.Errorf("2, finding %+v", f)
+	}
+	if f := SharedFactors([]*big.Int{w.strong.N, w.small.N}); f[0] != nil || f[1] != nil {
+		t.Errorf("unrelated moduli share factors: %+v %+v", f[0], f[1])
+	}
+}
+
+func TestCheck(t *testing.T) {
+	w := newWeakKeys(t)
+	keys := []*Key{w.strong, w.small, w.fermat, w.wiener, w.pm1}
+	keys = append(keys, w.shared...)
+	r := New(DefaultOptions).Check(keys)
+	want := [][]string{
+		nil,
+		{CheckSmallFactor},
+		{CheckFermat},
+		{CheckWiener},
+		{CheckPM1},
+		{CheckSharedFactor},
+		{CheckSharedFactor},
+		{CheckSharedFactor},
+	}
+	if r.Weak != 7 || len(r.Keys) != len(want) {
+		t.Fatalf("Check found %d weak keys of %d", r.Weak, len(r.Keys))
+	}
+	for i, k := range r.Keys {
+		var checks []string
+		for _, f := range k.Findings {
+			checks = append(checks, f.Check)
+		}
+		if strings.Join(checks, " ") != strings.Join(want[i], " ") || k.Index != i || k.Bits != keys[i].N.BitLen() {
+			t.Errorf("#%d, key report %d (%d bits) found %v; want %v", i, k.Index, k.Bits, checks, want[i])
+		}
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var back Report
+	if err := json.Unmarshal(b.Bytes(), &back); err != nil || back.Weak != r.Weak || back.Keys[3].E != keys[3].E.String() {
+		t.Errorf("JSON round trip: %v, %+v", err, back)
+	}
+	if !strings.Contains(b.String(), `"findings": []`) {
+		t.Errorf("JSON of the strong key has no empty findings array")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	w := newWeakKeys(t)
+	pkix1, err := x509.MarshalPKIXPublicKey(&rsa.PublicKey{N: w.strong.N, E: 65537})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1, err := asn1.Marshal(*w.wiener) // an exponent crypto/x509 would refuse
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Unix(0, 0), NotAfter: time.Unix(1<<31, 0)}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := asn1.Marshal(publicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}},
+		PublicKey: asn1.BitString{Bytes: []byte{4}, BitLength: 8},
+	})
+
+	var data []byte
+	for _, b := range []*pem.Block{
+		{Type: "PUBLIC KEY", Bytes: pkix1},
+		{Type: "EC PARAMETERS", Bytes: []byte{6}},
+		{Type: "RSA PUBLIC KEY", Bytes: pkcs1},
+		{Type: "CERTIFICATE", Bytes: cert},
+	} {
+		data = append(data, pem.EncodeToMemory(b)...)
+	}
+	want := []*Key{w.strong, w.wiener, KeyOf(&priv.PublicKey)}
+	for i, a := range []struct {
+		data []byte
+		want []*Key
+	}{
+		{data, want},
+		{pkix1, want[:1]},
+		{pkcs1, want[1:2]},
+		{cert, want[2:]},
+	} {
+		keys, err := ParseKeys(a.data)
+		if err != nil || len(keys) != len(a.want) {
+			t.Errorf("#%d, ParseKeys: %d keys, %v; want %d", i, len(keys), err, len(a.want))
+			continue
+		}
+		for j, k := range keys {
+			if k.N.Cmp(a.want[j].N) != 0 || k.E.Cmp(a.want[j].E) != 0 {
+				t.Errorf("#%d, key %d = %v, %v; want %v, %v", i, j, k.N, k.E, a.want[j].N, a.want[j].E)
+			}
+		}
+	}
+
+	for i, data := range [][]byte{
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: other}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pkcs1[:len(pkcs1)-1]}),
+		other,
+		[]byte("not a key"),
+	} {
+		if keys, err := ParseKeys(data); err == nil {
+			t.Errorf("#%d, ParseKeys accepted %d keys", i, len(keys))
+		}
+	}
+	bad := append(pem.EncodeToMemory(&pem.Block{Type: "EC PARAMETERS", Bytes: []byte{6}}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: other})...)
+	if _, err := ParseKeys(bad); err == nil || !strings.Contains(err.Error(), "public key block 1:") {
+		t.Errorf("ParseKeys(second block bad) = %v", err)
+	}
+	if keys, err := ParseKeys(pem.EncodeToMemory(&pem.Block{Type: "EC PARAMETERS", Bytes: []byte{6}})); err != nil || len(keys) != 0 {
+		t.Errorf("ParseKeys(no RSA blocks) = %v, %v", keys, err)
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	w := newWeakKeys(b)
+	c := New(DefaultOptions)
+	keys := []*Key{w.strong}
+	for b.Loop() {
+		c.Check(keys)
+	}
+}
+
+func ExampleChecker_Check() {
+	// 1000003 and 1000033 are close enough for Fermat's method to find at once.
+	n := big.NewInt(1000003 * 1000033)
+	c := New(Options{SmallBound: 1000, FermatIterations: 10, PM1Bound: 100})
+	r := c.Check([]*Key{{N: n, E: big.NewInt(65537)}})
+	r.WriteJSON(os.Stdout)
+	// Output:
+	// {
+	//   "keys": [
+	//     {
+	//       "index": 0,
+	//       "fingerprint": "19704cb7c472c584da5476aa1509be332b44b73490eb746ffa51fff183371cfb",
+	//       "bits": 40,
+	//       "e": "65537",
+	//       "findings": [
+	//         {
+	//           "check": "fermat",
+	//           "detail": "primes differ by 30, found after 0 steps",
+	//           "p": "1000003",
+	//           "q": "1000033"
+	//         }
+	//       ]
+	//     }
+	//   ],
+	//   "weak": 1
+	// }
+}
@@ -0,0 +1,194 @@
+package sieve
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// safeReach bounds the sieve primes used by SafePrimeBig. Both p and q must survive, so
+// sieving pays for itself further than in NextPrimeBig.
+const safeReach = 1 << 18
+
+// SafePrimeBig returns a safe prime p = 2q+1, with q also prime, of exactly the given
+// number of bits, reading randomness from rand. A random odd start for q is chosen, and
+// candidates q, q+2, ... are sieved for q and 2q+1 together with the same residues, so
+// each small prime strikes the candidates for which it divides either. Only one candidate
+// in several hundred survives at 2048 bits, and survivors are screened by a base-2 Fermat
+// test of p before the full tests of both. Since q is odd, 5 is never returned.
+func (sieve *Sieve) SafePrimeBig(bits int, rand io.Reader) (*big.Int, error) {
+	if bits < 3 {
+		return nil, errors.New("sieve: safe prime size must be at least 3 bits")
+	}
+	b := make([]byte, (bits-1+7)/8)
+	two := big.NewInt(2)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		b[0] &= byte(1<<((bits-2)%8+1) - 1) // clear bits above the size of q
+		q := new(big.Int).SetBytes(b)
+		q.SetBit(q, bits-2, 1)
+		q.SetBit(q, 0, 1)
+
+		// primes must be smaller than q, which is at least 2^(bits-2)
+		reach := safeReach
+		if bits-2 < 20 {
+			reach = min(reach, 1<<(bits-2)-1)
+		}
+		var p, c *big.Int
+		var e big.Int
+		sieve.sieveBig(q, reach, [][2]uint64{{1, 0}, {2, 1}}, func(j int) bool {
+			c = new(big.Int).Add(q, big.NewInt(int64(2*j)))
+			if c.BitLen() >= bits {
+				return false // ran past the size
+			}
+			p = new(big.Int).Lsh(c, 1)
+			p.Add(p, bigOne)
+			if e.Exp(two, c, p).Cmp(bigOne) == 0 || e.Add(&e, bigOne).Cmp(p) == 0 { // 2^q ≡ ±1 (mod p)
+				if sieve.probablyPrime(c) && sieve.probablyPrime(p) {
+					return false
+				}
+			}
+			p = nil
+			return true
+		})
+		if p != nil {
+			return p, nil
+		}
+	}
+}
+
+// SubgroupGenerator returns a generator of the subgroup of prime order q of the
+// multiplicative group modulo the prime p, where q divides p-1, reading randomness
+// from rand. It raises random h to the power (p-1)/q until the result is not 1; as q is
+// prime, any such result has order q.
+func SubgroupGenerator(p, q *big.Int, rand io.Reader) (*big.Int, error) {
+	if q.Sign() <= 0 {
+		return nil, errors.New("sieve: q does not divide p-1")
+	}
+	k, r := new(big.Int).QuoRem(new(big.Int).Sub(p, bigOne), q, new(big.Int))
+	if r.Sign() != 0 {
+		return nil, errors.New("sieve: q does not divide p-1")
+	}
+	b := make([]byte, (p.BitLen()+7)/8+8) // extra bytes make h nearly uniform
+	span := new(big.Int).Sub(p, big.NewInt(3))
+	if span.Sign() <= 0 {
+		return nil, errors.New("sieve: p too small")
+	}
+	for range 1000 {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		h := new(big.Int).SetBytes(b)
+		h.Mod(h, span).Add(h, big.NewInt(2)) // 2 <= h <= p-2
+		if g := h.Exp(h, k, p); g.Cmp(bigOne) != 0 {
+			return g, nil
+		}
+	}
+	return nil, errors.New("sieve: no generator found; is p prime?")
+}
+
+// VerifyDHGroup checks finite-field Diffie-Hellman or DSA parameters: that p and q are
+// prime, that q divides p-1, and that g generates the subgroup of order q, which is to
+// say 1 < g < p and g^q ≡ 1 (mod p). It returns nil for a valid group and otherwise an
+// error naming the first failure.
+func (sieve *Sieve) VerifyDHGroup(p, q, g *big.Int) error {
+	switch {
+	case !sieve.PrimeBig(p):
+		return errors.New("sieve: p is not prime")
+	case !sieve.PrimeBig(q):
+		return errors.New("sieve: q is not prime")
+	case new(big.Int).Mod(new(big.Int).Sub(p, bigOne), q).Sign() != 0:
+		return errors.New("sieve: q does not divide p-1")
+	case g.Cmp(bigOne) <= 0 || g.Cmp(p) >= 0:
+		return fmt.Errorf("sieve: generator %v outside (1, p)", g)
+	case new(big.Int).Exp(g, q, p).Cmp(bigOne) != 0:
+		return errors.New("sieve: generator's order is not q")
+	}
+	return nil
+}
+
+// DHGroup generates Diffie-Hellman parameters of the given size: a safe prime p, the
+// prime q = (p-1)/2, and a generator g of the subgroup of order q, the quadratic
+// residues, in which the decisional Diffie-Hellman problem is believed hard.
+func (sieve *Sieve) DHGroup(bits int, rand io.Reader) (p, q, g *big.Int, err error) {
+	if p, err = sieve.SafePrimeBig(bits, rand); err != nil {
+		return nil, nil, nil, err
+	}
+	q = new(big.Int).Rsh(p, 1)
+	if g, err = SubgroupGenerator(p, q, rand); err != nil {
+		return nil, nil, nil, err
+	}
+	return p, q, g, nil
+}
+
+// DSAGroup generates DSA-style parameters with an lBits prime p, an nBits prime q
+// dividing p-1, and a generator g of the subgroup of order q, in the manner of FIPS
+// 186: with q chosen, random X of lBits bits are reduced to p = X - (X mod 2q) + 1
+// until p is prime. After 4*lBits failed candidates a new q is chosen, since when the
+// sizes are close there may be few or no primes p of exactly lBits bits for a given q.
+func (sieve *Sieve) DSAGroup(lBits, nBits int, rand io.Reader) (p, q, g *big.Int, err error) {
+	if nBits < 2 || lBits <= nBits {
+		return nil, nil, nil, errors.New("sieve: DSA sizes must satisfy 2 <= nBits < lBits")
+	}
+	b := make([]byte, (lBits+7)/8)
+	var r big.Int
+	for p == nil {
+		if q, err = sieve.RandomPrime(nBits, rand); err != nil {
+			return nil, nil, nil, err
+		}
+		q2 := new(big.Int).Lsh(q, 1)
+		for range 4 * lBits {
+			if _, err = io.ReadFull(rand, b); err != nil {
+				return nil, nil, nil, err
+			}
+			b[0] &= byte(1<<((lBits-1)%8+1) - 1)
+			c := new(big.Int).SetBytes(b)
+			c.SetBit(c, lBits-1, 1)
+			c.Sub(c, r.Mod(c, q2)).Add(c, bigOne)
+			if c.BitLen() == lBits && sieve.probablyPrime(c) {
+				p = c
+				break
+			}
+		}
+	}
+	if g, err = SubgroupGenerator(p, q, rand); err != nil {
+		return nil, nil, nil, err
+	}
+	return p, q, g, nil
+}
+
+// SeededReader is a deterministic stream of pseudorandom bytes, SHA-256 applied to a
+// seed and a block counter, for reproducing parameters in tests. It is not a substitute
+// for crypto/rand.Reader when generating real keys.
+type SeededReader struct {
+	seed    []byte
+	counter uint64
+	block   []byte // unread bytes of the current block
+}
+
+// NewSeededReader returns a reader whose output is determined by seed.
+func NewSeededReader(seed []byte) *SeededReader {
+	return &SeededReader{seed: append([]byte(nil), seed...)}
+}
+
+// Read fills b from the stream; it never fails.
+func (r *SeededReader) Read(b []byte) (int, error) {
+	for n := 0; n < len(b); {
+		if len(r.block) == 0 {
+			var count [8]byte
+			binary.BigEndian.PutUint64(count[:], r.counter)
+			r.counter++
+			sum := sha256.Sum256(append(append([]byte(nil), r.seed...), count[:]...))
+			r.block = sum[:]
+		}
+		k := copy(b[n:], r.block)
+		r.block = r.block[k:]
+		n += k
+	}
+	return len(b), nil
+}
@@ -0,0 +1,129 @@
+package sieve
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSafePrimeBig(t *testing.T) {
+	sieve := New(1 << 18)
+	r := NewSeededReader([]byte("safe"))
+	for _, bits := range []int{3, 4, 5, 6, 8, 12, 20, 21, 22, 32, 64, 65, 128, 256} {
+		for range 4 {
+			p, err := sieve.SafePrimeBig(bits, r)
+			if err != nil || p.BitLen() != bits {
+				t.Errorf("SafePrimeBig(%d) = %v, %v", bits, p, err)
+				continue
+			}
+			q := new(big.Int).Rsh(p, 1)
+			if !p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+				t.Errorf("SafePrimeBig(%d) = %v is not a safe prime", bits, p)
+			}
+		}
+	}
+	if _, err := sieve.SafePrimeBig(2, r); err == nil {
+		t.Errorf("SafePrimeBig(2) succeeded")
+	}
+	if _, err := sieve.SafePrimeBig(64, bytes.NewReader(nil)); err == nil {
+		t.Errorf("SafePrimeBig with empty reader succeeded")
+	}
+}
+
+func TestDHGroup(t *testing.T) {
+	sieve := New(1 << 18)
+	p, q, g, err := sieve.DHGroup(256, NewSeededReader([]byte("group")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sieve.VerifyDHGroup(p, q, g); err != nil {
+		t.Errorf("DHGroup(256) = %v, %v, %v: %v", p, q, g, err)
+	}
+	p2, q2, g2, _ := sieve.DHGroup(256, NewSeededReader([]byte("group")))
+	if p.Cmp(p2) != 0 || q.Cmp(q2) != 0 || g.Cmp(g2) != 0 {
+		t.Errorf("DHGroup is not reproducible from its seed")
+	}
+
+	p, q, g, err = sieve.DSAGroup(512, 160, NewSeededReader([]byte("dsa")))
+	if err != nil || p.BitLen() != 512 || q.BitLen() != 160 {
+		t.Fatalf("DSAGroup(512, 160) = %v, %v, %v, %v", p, q, g, err)
+	}
+	if err := sieve.VerifyDHGroup(p, q, g); err != nil {
+		t.Errorf("DSAGroup(512, 160): %v", err)
+	}
+
+	// With sizes this close, many q admit no prime p of exactly lBits bits.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, a := range [][2]int{{3, 2}, {17, 16}, {18, 16}, {19, 16}, {65, 64}, {66, 64}} {
+			p, q, g, err := sieve.DSAGroup(a[0], a[1], NewSeededReader([]byte{1}))
+			if err != nil || p.BitLen() != a[0] || q.BitLen() != a[1] {
+				t.Errorf("DSAGroup(%d, %d) = %v, %v, %v, %v", a[0], a[1], p, q, g, err)
+				continue
+			}
+			if err := sieve.VerifyDHGroup(p, q, g); err != nil {
+				t.Errorf("DSAGroup(%d, %d): %v", a[0], a[1], err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("DSAGroup with close sizes did not finish")
+	}
+
+	for i, a := range []struct{ p, q, g int64 }{
+		{23, 11, 4},  // valid
+		{23, 11, 5},  // 5 has order 22
+		{23, 7, 4},   // 7 does not divide 22
+		{21, 5, 4},   // 21 is not prime
+		{23, 11, 1},  // trivial generator
+		{23, 11, 23}, // out of range
+		{47, 23, 2},  // valid: 2 is a quadratic residue mod 47
+	} {
+		err := sieve.VerifyDHGroup(big.NewInt(a.p), big.NewInt(a.q), big.NewInt(a.g))
+		if (err == nil) != (i == 0 || i == 6) {
+			t.Errorf("#%d, VerifyDHGroup(%d, %d, %d) = %v", i, a.p, a.q, a.g, err)
+		}
+	}
+	for _, q := range []int64{7, 0, -11} {
+		if _, err := SubgroupGenerator(big.NewInt(23), big.NewInt(q), NewSeededReader(nil)); err == nil {
+			t.Errorf("SubgroupGenerator(23, %d) succeeded", q)
+		}
+	}
+}
+
+func TestSeededReader(t *testing.T) {
+	a, b := make([]byte, 100), make([]byte, 100)
+	NewSeededReader([]byte("x")).Read(a)
+	r := NewSeededReader([]byte("x"))
+	for i := 0; i < len(b); i += 7 {
+		r.Read(b[i:min(i+7, len(b))])
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("SeededReader depends on read sizes")
+	}
+	NewSeededReader([]byte("y")).Read(b)
+	if bytes.Equal(a, b) {
+		t.Errorf("SeededReader ignores its seed")
+	}
+}
+
+func BenchmarkSafePrimeBig512(b *testing.B) {
+	sieve := New(1 << 18)
+	r := NewSeededReader(nil)
+	for b.Loop() {
+		sieve.SafePrimeBig(512, r)
+	}
+}
+
+func ExampleSieve_SafePrimeBig() {
+	p, _ := New(1<<18).SafePrimeBig(64, NewSeededReader([]byte("example")))
+	q := new(big.Int).Rsh(p, 1)
+	fmt.Println(p.BitLen(), p.ProbablyPrime(20), q.ProbablyPrime(20))
+	// Output:
+	// 64 true true
+}
@@ -0,0 +1,118 @@
+package sieve
+
+// segment is a sieved window onto the odd numbers of [lo, hi], laid out one bit per odd
+// number just as in Sieve.table, with set bits marking composites. Striking uses the
+// base sieve's primes; when those do not reach sqrt(hi) the window is not exact and its
+// survivors are confirmed one at a time with a deterministic test. This is how the
+// package reaches numbers far beyond the in-memory sieve.
+type segment struct {
+	lo, hi uint64 // lo is odd and bit i represents lo+2*i
+	exact  bool   // struck by every prime <= sqrt(hi)
+	table  []word
+}
+
+// newSegment sieves the odd numbers of [lo, hi] with the base sieve's odd primes no
+// larger than bound. A bound of zero means "as many as are useful".
+func (sieve *Sieve) newSegment(lo, hi, bound uint64) *segment {
+	if lo < 1 {
+		lo = 1
+	}
+	lo |= 1 // first odd number >= lo
+	seg := &segment{lo: lo, hi: hi}
+	if hi < lo {
+		seg.hi = lo - 1
+		return seg
+	}
+	n := (hi-lo)/2 + 1 // odd numbers in window
+	seg.table = make([]word, (n+wordBits-1)/wordBits)
+	if lo == 1 {
+		seg.table[0] |= 1 // one is not prime
+	}
+
+	root := isqrt64(hi)
+	limit := root
+	if bound != 0 && bound < limit {
+		limit = bound
+	}
+	if limit > uint64(sieve.size) {
+		limit = uint64(sieve.size)
+	}
+	seg.exact = limit == root
+
+	for p := uint64(3); p <= limit; p += 2 {
+		if sieve.bit(int(p)) != 0 {
+			continue // not a prime
+		}
+		start := p * p // smaller multiples have smaller factors
+		if start < lo {
+			start = lo // first multiple of p >= lo
+			if r := lo % p; r != 0 {
+				start += p - r
+			}
+			if start&1 == 0 {
+				start += p // odd multiples only
+			}
+		}
+		for i := (start - lo) / 2; i < n; i += p {
+			seg.table[i>>wordBitsLog2] |= word(1) << (i & wordMask) // strike multiples from table
+		}
+	}
+	return seg
+}
+
+// composite reports whether the odd number n in [lo, hi] was struck by a base prime.
+func (seg *segment) composite(n uint64) bool {
+	i := (n - seg.lo) >> 1
+	return seg.table[i>>wordBitsLog2]>>(i&wordMask)&1 != 0
+}
+
+// prime reports whether the odd number n in [lo, hi] is prime.
+func (seg *segment) prime(n uint64) bool {
+	return !seg.composite(n) && (seg.exact || isPrime64(n))
+}
+
+// whole presents the sieve's own table as an exact segment over [1, Size()]. Note that
+// the table does not strike 1, so callers must not ask about it.
+func (sieve *Sieve) whole() *segment {
+	return &segment{lo: 1, hi: uint64(sieve.size), exact: true, table: sieve.table}
+}
+
+// survivors returns the unstruck flags of the sixty-four odd numbers n, n+2, ..., n+126
+// as bits 0 through 63. Numbers outside the window read as struck.
+func (seg *segment) survivors(n uint64) uint64 {
+	if n < seg.lo || n > seg.hi {
+		return 0
+	}
+	i := (n - seg.lo) >> 1
+	count := (seg.hi-seg.lo)/2 + 1 - i // odd numbers from n to hi
+	var result uint64
+	for k := uint64(0); k < 64 && k < count; {
+		w := i + k
+		offset := w & wordMask
+		result |= uint64(^seg.table[w>>wordBitsLog2]>>offset) << k
+		k += wordBits - offset
+	}
+	if count < 64 {
+		result &= 1<<count - 1
+	}
+	return result
+}
+
+// doubled returns bits like survivors for the numbers 2n+c, 2n+c+4, ..., 2n+c+252: the
+// odd numbers 2m+c for m = n, n+2, ..., n+126. These occupy every other bit of the table,
+// so two extractions are made and their alternate bits gathered.
+func (seg *segment) doubled(n uint64, c int) uint64 {
+	m := 2*n + uint64(c)
+	return evenBits(seg.survivors(m)) | evenBits(seg.survivors(m+128))<<32
+}
+
+// evenBits gathers bits 0, 2, 4, ..., 62 of x into bits 0 through 31.
+func evenBits(x uint64) uint64 {
+	x &= 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x>>4) & 0x00ff00ff00ff00ff
+	x = (x | x>>8) & 0x0000ffff0000ffff
+	x = (x | x>>16) & 0x00000000ffffffff
+	return x
+}
@@ -91,6 +91,20 @@ func (sieve *Sieve) Size() int {
 	return sieve.size
 }
 
+// reach returns Size()*Size(), the reach of factoring by trial division, or the largest
+// int if the product would overflow, as it may for very large sieves.
+func (sieve *Sieve) reach() int {
+	if sieve.size > math.MaxInt/max(sieve.size, 1) {
+		return math.MaxInt
+	}
+	return sieve.size * sieve.size
+}
+
+// reaches reports whether n is within the reach of factoring by trial division.
+func (sieve *Sieve) reaches(n int) bool {
+	return n <= sieve.reach()
+}
+
 // Count the number of primes in the sieve.
 func (sieve *Sieve) Count() int {
 	if sieve.count == 0 {
@@ -106,9 +120,10 @@ func (sieve *Sieve) Count() int {
 	return sieve.count
 }
 
-// Prime tests primality using the sieve for precomputed answer. Testing values outside
-// the range of the sieve returns false, indicating that this sieve cannot prove the
-// number to be prime. For values inside the range, the result is definitive.
+// Prime tests primality using the sieve for precomputed answer. Values beyond the sieve
+// are tested by trial division up to Size()*Size() and, past that, by trial division
+// with the smaller primes followed by the Baillie-PSW test, which has no exceptions
+// among 64-bit integers. The result is definitive throughout.
 func (sieve *Sieve) Prime(n int) bool {
 	switch {
 	case n < 2:
@@ -120,12 +135,9 @@ func (sieve *Sieve) Prime(n int) bool {
 	case n <= sieve.size:
 		// determine primality by direct inspection
 		return n == 2 || (n > 2 && n <= sieve.size && n&1 == 1 && sieve.bit(n) == 0)
-	case n <= sieve.size*sieve.size:
+	case sieve.reaches(n):
 		// determine primality by trial division
-		root := 1
-		for (root+1)*(root+1) <= n {
-			root++
-		}
+		root := int(isqrt64(uint64(n)))
 		if root*root == n { // perfect square
 			return false
 		}
@@ -136,7 +148,8 @@ func (sieve *Sieve) Prime(n int) bool {
 		}
 		return true
 	default:
-		return false
+		// determine primality by trial division and the Baillie-PSW test
+		return sieve.bpsw(uint64(n))
 	}
 }
 
@@ -165,7 +178,7 @@ func (sieve *Sieve) String() string {
 // Factor an integer <= sieve.Size()*sieve.Size() using the sieve for trial divisors.
 // Returns a slice of factors. Repeated factors are repeated in the result.
 func (sieve *Sieve) Factor(n int) []int {
-	if sieve.size*sieve.size < n { // too big for sieve?
+	if !sieve.reaches(n) { // too big for sieve?
 		return make([]int, 0, 0)
 	}
 	if n <= 3 {
@@ -203,7 +216,7 @@ type Unique struct {
 // Factor an integer <= sieve.Size()*sieve.Size() using the sieve for trial divisors.
 // Returns a slice of factors. Repeated factors are repeated in the result.
 func (sieve *Sieve) FactorUnique(n int) []Unique {
-	if sieve.size*sieve.size < n { // too big for sieve?
+	if !sieve.reaches(n) { // too big for sieve?
 		return make([]Unique, 0, 0)
 	}
 	if n <= 3 {
@@ -260,7 +273,7 @@ func (sieve *Sieve) FactorString(n int) string {
 // Determine the total number of divisors of n
 // Divisors(6) == 4, from {1, 2, 3, 6}
 func (sieve *Sieve) DivisorCount(n int) int {
-	if sieve.size*sieve.size < n { // too big for sieve?
+	if !sieve.reaches(n) { // too big for sieve?
 		return 0
 	}
 	if n == 1 {
@@ -300,7 +313,7 @@ func (sieve *Sieve) DivisorCount(n int) int {
 // SquareFree is a boolean test that the subject number's factors are not repeated.
 // Square-free numbers are the sequence http://oeis.org/A005117
 func (sieve *Sieve) SquareFree(n int) bool {
-	if sieve.size*sieve.size < n { // too big for sieve?
+	if !sieve.reaches(n) { // too big for sieve?
 		return false
 	}
 	f := sieve.Factor(n)
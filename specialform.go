@@ -0,0 +1,243 @@
+package sieve
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/MichaelTJones/sieve/modarith"
+)
+
+// Numbers of the forms k·2^n±1 have tests of their own, each a chain of n squarings in
+// which reduction modulo N costs only shifts and additions. Before any squaring, the
+// candidates are trial divided by primes that could divide them: 2kp+1 for Mersenne
+// numbers (Fermat, 1640), k·2^(m+2)+1 for Fermat numbers (Euler and Lucas), and the
+// primes of the sieve otherwise.
+
+// specialReach bounds the sieve primes used to trial divide k·2^n±1, and specialBound
+// the candidate divisors of the special forms of Mersenne and Fermat numbers.
+const (
+	specialReach = 1 << 16
+	specialBound = 1 << 26
+)
+
+// special is the modulus N = k·2^n + c for c = ±1 and reduces modulo it by shifts. With
+// x = q·2^n + r and q = a·k + b, k·2^n ≡ -c gives x ≡ b·2^n + r - c·a, and each step
+// shortens x by about the length of N until it exceeds N by at most a bit.
+type special struct {
+	N, k    *big.Int
+	n       uint
+	c       int
+	mask    *big.Int
+	a, b, r big.Int
+}
+
+// newSpecial returns the modulus k·2^n + c.
+func newSpecial(k uint64, n uint, c int) *special {
+	s := &special{k: new(big.Int).SetUint64(k), n: n, c: c}
+	s.N = new(big.Int).Lsh(s.k, n)
+	s.N.Add(s.N, big.NewInt(int64(c)))
+	s.mask = new(big.Int).Lsh(bigOne, n)
+	s.mask.Sub(s.mask, bigOne)
+	return s
+}
+
+// reduce sets x to x mod N, in [0, N), and returns it. Negative x are allowed.
+func (s *special) reduce(x *big.Int) *big.Int {
+	for x.BitLen() > s.N.BitLen()+1 {
+		s.r.And(x, s.mask) // two's complement for negative x, matching the shift below
+		x.Rsh(x, s.n)
+		if s.k.Cmp(bigOne) == 0 {
+			s.a.Set(x)
+			s.b.SetInt64(0)
+		} else {
+			s.a.DivMod(x, s.k, &s.b)
+		}
+		x.Lsh(&s.b, s.n).Add(x, &s.r)
+		if s.c > 0 {
+			x.Sub(x, &s.a)
+		} else {
+			x.Add(x, &s.a)
+		}
+	}
+	return x.Mod(x, s.N)
+}
+
+// divisible reports whether a sieve prime below specialReach divides k·2^n + c, which
+// must exceed them.
+func (sieve *Sieve) divisible(k uint64, n uint, c int) bool {
+	for p := 3; p <= min(sieve.size, specialReach); p += 2 {
+		if sieve.bit(p) != 0 {
+			continue
+		}
+		q := uint64(p)
+		r := modarith.MulMod(k%q, modarith.PowMod(2, uint64(n), q), q)
+		if (r+q+uint64(c))%q == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mersenneFactor returns the smallest divisor 2kp+1 of 2^p-1, for prime p >= 64, below
+// specialBound, or 0 if there is none. Such divisors are ±1 mod 8, since 2 is a square
+// modulo them, and they need not be tested for primality, as the least is prime.
+func mersenneFactor(p int) uint64 {
+	for q := uint64(2*p + 1); q < specialBound; q += uint64(2 * p) {
+		if (q&7 == 1 || q&7 == 7) && modarith.PowMod(2, uint64(p), q) == 1 {
+			return q
+		}
+	}
+	return 0
+}
+
+// LucasLehmer reports whether the Mersenne number 2^p-1 is prime. The exponent must be
+// prime, as 2^a-1 divides 2^ab-1, and divisors 2kp+1 are sought before the test of Lucas
+// (1878) and Lehmer (1930): with s₀ = 4 and s_{i+1} = s_i²-2, the number 2^p-1 for odd
+// prime p is prime exactly when it divides s_{p-2}.
+func (sieve *Sieve) LucasLehmer(p int) bool {
+	switch {
+	case p == 2:
+		return true
+	case !sieve.Prime(p):
+		return false
+	case p < 64:
+		return isPrime64(1<<p - 1)
+	case mersenneFactor(p) != 0:
+		return false
+	}
+	m := newSpecial(1, uint(p), -1)
+	s, two := big.NewInt(4), big.NewInt(2)
+	for range p - 2 {
+		m.reduce(s.Mul(s, s).Sub(s, two))
+	}
+	return s.Sign() == 0
+}
+
+// Proth reports whether N = k·2^n+1 is prime. Powers of two in k are moved into n. For
+// odd k < 2^n, Proth's theorem (1878) holds that N is prime exactly when
+// a^((N-1)/2) ≡ -1 (mod N) for a quadratic nonresidue a, which a search of small primes
+// by their Jacobi symbols finds. Small N are tested directly and those with k ≥ 2^n by
+// PrimeBig.
+func (sieve *Sieve) Proth(k uint64, n int) bool {
+	if k == 0 || n < 0 {
+		return false
+	}
+	z := bits.TrailingZeros64(k)
+	k, n = k>>z, n+z
+	switch N := newSpecial(k, uint(n), 1); {
+	case N.N.IsUint64():
+		return sieve.isPrime(N.N.Uint64())
+	case n < 64 && k>>n != 0:
+		return sieve.PrimeBig(N.N)
+	case sieve.divisible(k, uint(n), 1):
+		return false
+	default:
+		a := sieve.nonresidue(N.N)
+		if a == nil {
+			return sieve.PrimeBig(N.N) // only a square lacks one among small primes
+		}
+		x := new(big.Int).Exp(a, N.k, N.N)
+		for range n - 1 {
+			N.reduce(x.Mul(x, x))
+		}
+		return x.Add(x, bigOne).Cmp(N.N) == 0
+	}
+}
+
+// nonresidue returns the least prime a with Jacobi symbol (a/N) = -1, or nil if none
+// is found among the sieve primes below trialReach or some such prime divides N.
+func (sieve *Sieve) nonresidue(N *big.Int) *big.Int {
+	var a big.Int
+	for p := 3; p <= min(sieve.size, trialReach); p += 2 {
+		if sieve.bit(p) != 0 {
+			continue
+		}
+		switch big.Jacobi(a.SetInt64(int64(p)), N) {
+		case -1:
+			return &a
+		case 0:
+			return nil
+		}
+	}
+	return nil
+}
+
+// Pepin reports whether the Fermat number F_m = 2^(2^m)+1 is prime. Divisors of the form
+// k·2^(m+2)+1 are sought first, then Pépin's test (1877): F_m, m ≥ 1, is prime exactly
+// when 3^((F_m-1)/2) ≡ -1 (mod F_m). Only F_0 through F_4 are known to be prime.
+func (sieve *Sieve) Pepin(m int) bool {
+	switch {
+	case m < 0:
+		return false
+	case m <= 5:
+		return isPrime64(1<<(1<<m) + 1)
+	case m < 62 && fermatFactor(m) != 0:
+		return false
+	}
+	N := newSpecial(1, 1<<m, 1)
+	x := big.NewInt(3)
+	for range 1<<m - 1 {
+		N.reduce(x.Mul(x, x))
+	}
+	return x.Add(x, bigOne).Cmp(N.N) == 0
+}
+
+// fermatFactor returns the smallest divisor k·2^(m+2)+1 of F_m, m > 5, below
+// specialBound, or 0 if there is none.
+func fermatFactor(m int) uint64 {
+	for q := uint64(1)<<(m+2) + 1; q < specialBound; q += 1 << (m + 2) {
+		x := uint64(2)
+		for range m {
+			x = modarith.MulMod(x, x, q)
+		}
+		if x == q-1 {
+			return q
+		}
+	}
+	return 0
+}
+
+// LLR reports whether N = k·2^n-1 is prime by the Lucas-Lehmer-Riesel test. Powers of
+// two in k are moved into n. For odd k < 2^n, with P chosen so that the Jacobi symbols
+// ((P-2)/N) = 1 and ((P+2)/N) = -1 (Rödseth, 1994), u₀ = V_k(P, 1) mod N and
+// u_{i+1} = u_i²-2, N is prime exactly when it divides u_{n-2}. For k = 1 this is the
+// Lucas-Lehmer test. Small N are tested directly and those with k ≥ 2^n by PrimeBig.
+func (sieve *Sieve) LLR(k uint64, n int) bool {
+	if k == 0 || n < 0 {
+		return false
+	}
+	z := bits.TrailingZeros64(k)
+	k, n = k>>z, n+z
+	N := newSpecial(k, uint(n), -1)
+	switch {
+	case N.N.IsUint64():
+		return sieve.isPrime(N.N.Uint64())
+	case n < 64 && k>>n != 0:
+		return sieve.PrimeBig(N.N)
+	case k == 1 && (!sieve.Prime(n) || mersenneFactor(n) != 0):
+		return false
+	case sieve.divisible(k, uint(n), -1):
+		return false
+	}
+
+	var a, b big.Int
+	P := int64(3)
+	for ; ; P++ {
+		j, l := big.Jacobi(a.SetInt64(P-2), N.N), big.Jacobi(b.SetInt64(P+2), N.N)
+		if j == 0 || l == 0 {
+			return false // P±2 is small, and N is not
+		}
+		if j == 1 && l == -1 {
+			break
+		}
+		if P > trialReach {
+			return sieve.PrimeBig(N.N) // only a square lacks such P
+		}
+	}
+	_, u, _ := LucasSequence(P, 1, N.k, N.N)
+	two := big.NewInt(2)
+	for range n - 2 {
+		N.reduce(u.Mul(u, u).Sub(u, two))
+	}
+	return u.Sign() == 0
+}
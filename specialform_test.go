@@ -0,0 +1,154 @@
+package sieve
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSpecialReduce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i, a := range []struct {
+		k uint64
+		n uint
+		c int
+	}{
+		{1, 61, -1}, {1, 64, 1}, {1, 127, -1}, {3, 100, 1}, {3, 100, -1}, {1<<32 - 5, 77, 1}, {1<<63 - 25, 200, -1},
+	} {
+		s := newSpecial(a.k, a.n, a.c)
+		for range 100 {
+			x := new(big.Int).Rand(r, new(big.Int).Lsh(s.N, uint(r.Intn(3*int(a.n)))))
+			if r.Intn(2) == 0 {
+				x.Neg(x)
+			}
+			want := new(big.Int).Mod(x, s.N)
+			if have := s.reduce(new(big.Int).Set(x)); have.Cmp(want) != 0 {
+				t.Errorf("#%d, %v mod %d·2^%d%+d = %v; want %v", i, x, a.k, a.n, a.c, have, want)
+			}
+		}
+	}
+}
+
+func TestLucasLehmer(t *testing.T) {
+	sieve := New(1 << 16)
+	var have []int
+	for p := range 1300 {
+		if sieve.LucasLehmer(p) {
+			have = append(have, p)
+		}
+	}
+	want := []int{2, 3, 5, 7, 13, 17, 19, 31, 61, 89, 107, 127, 521, 607, 1279} // A000043
+	if !slices.Equal(have, want) {
+		t.Errorf("Mersenne exponents below 1300 = %v; want %v", have, want)
+	}
+}
+
+func TestMersenneFactor(t *testing.T) {
+	for i, a := range []struct {
+		p int
+		q uint64
+	}{
+		{67, 0}, {71, 228479}, {73, 439}, {79, 2687}, {83, 167}, {89, 0}, {97, 11447}, {101, 0},
+	} {
+		if q := mersenneFactor(a.p); q != a.q {
+			t.Errorf("#%d, mersenneFactor(%d) = %d; want %d", i, a.p, q, a.q)
+		}
+	}
+}
+
+func TestProth(t *testing.T) {
+	sieve := New(1 << 16)
+	for k := uint64(1); k < 200; k++ {
+		for n := 0; n < 90; n++ {
+			N := new(big.Int).Lsh(new(big.Int).SetUint64(k), uint(n))
+			N.Add(N, bigOne)
+			if have, want := sieve.Proth(k, n), N.ProbablyPrime(20); have != want {
+				t.Errorf("Proth(%d, %d) = %v; want %v", k, n, have, want)
+			}
+		}
+	}
+	for i, a := range []struct {
+		k     uint64
+		n     int
+		prime bool
+	}{
+		{3, 189, true}, {3, 2208, true}, {3, 2209, false}, {5, 1947, true}, {1, 128, false}, {0, 10, false}, {3, -1, false},
+	} {
+		if prime := sieve.Proth(a.k, a.n); prime != a.prime {
+			t.Errorf("#%d, Proth(%d, %d) = %v; want %v", i, a.k, a.n, prime, a.prime)
+		}
+	}
+}
+
+func TestPepin(t *testing.T) {
+	sieve := New(1 << 16)
+	for m := range 13 {
+		if have, want := sieve.Pepin(m), m <= 4; have != want {
+			t.Errorf("Pepin(%d) = %v; want %v", m, have, want)
+		}
+	}
+	for i, a := range []struct {
+		m int
+		q uint64
+	}{
+		{6, 274177}, {9, 2424833}, {12, 114689}, {14, 0}, {23, 0},
+	} {
+		if q := fermatFactor(a.m); q != a.q {
+			t.Errorf("#%d, fermatFactor(%d) = %d; want %d", i, a.m, q, a.q)
+		}
+	}
+}
+
+func TestLLR(t *testing.T) {
+	sieve := New(1 << 16)
+	for k := uint64(1); k < 200; k++ {
+		for n := 0; n < 90; n++ {
+			N := new(big.Int).Lsh(new(big.Int).SetUint64(k), uint(n))
+			N.Sub(N, bigOne)
+			if have, want := sieve.LLR(k, n), N.ProbablyPrime(20); have != want {
+				t.Errorf("LLR(%d, %d) = %v; want %v", k, n, have, want)
+			}
+		}
+	}
+	for i, a := range []struct {
+		k     uint64
+		n     int
+		prime bool
+	}{
+		{1, 521, true}, {1, 523, false}, {3, 1274, true}, {3, 1278, false}, {5, 1340, true}, {5, 1341, false}, {0, 10, false}, {1, -1, false},
+	} {
+		if prime := sieve.LLR(a.k, a.n); prime != a.prime {
+			t.Errorf("#%d, LLR(%d, %d) = %v; want %v", i, a.k, a.n, prime, a.prime)
+		}
+	}
+}
+
+func BenchmarkLucasLehmer(b *testing.B) {
+	sieve := New(1 << 16)
+	for b.Loop() {
+		sieve.LucasLehmer(4423)
+	}
+}
+
+func BenchmarkProbablyPrimeMersenne(b *testing.B) {
+	n := new(big.Int).Lsh(bigOne, 4423)
+	n.Sub(n, bigOne)
+	for b.Loop() {
+		n.ProbablyPrime(0)
+	}
+}
+
+func ExampleSieve_LucasLehmer() {
+	sieve := New(1 << 16)
+	for _, p := range []int{61, 67, 89, 127, 4423} { // 2^67-1 = 193707721·761838257287
+		fmt.Printf("2^%d-1 %v\n", p, sieve.LucasLehmer(p))
+	}
+	// Output:
+	// 2^61-1 true
+	// 2^67-1 false
+	// 2^89-1 true
+	// 2^127-1 true
+	// 2^4423-1 true
+}
@@ -0,0 +1,226 @@
+package sieve
+
+import (
+	"iter"
+	"math"
+	"math/bits"
+)
+
+// A Stage transforms an ascending stream of primes into another ascending stream. Stages
+// compose, so that new sequences are defined in a line, as in
+//
+//	Compose(s.All(), Pairs(2), PrimeIndexed(1), Below(1000000))
+//
+// for the prime-indexed lesser twin primes below 10^6. Streams are produced lazily and
+// run beyond the sieve in segments, so bound them with Below or Take.
+type Stage func(iter.Seq[int]) iter.Seq[int]
+
+// All returns an iterator over all primes in order: those of the sieve, then those
+// found in segments beyond it.
+func (sieve *Sieve) All() iter.Seq[int] {
+	return sieve.primes(0, math.MaxInt)
+}
+
+// Compose applies the stages to seq in order.
+func Compose(seq iter.Seq[int], stages ...Stage) iter.Seq[int] {
+	for _, stage := range stages {
+		seq = stage(seq)
+	}
+	return seq
+}
+
+// Filter passes the members for which pred is true.
+func Filter(pred func(int) bool) Stage {
+	return func(seq iter.Seq[int]) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for p := range seq {
+				if pred(p) && !yield(p) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Below passes members up to n, ending the stream at the first member exceeding n.
+func Below(n int) Stage {
+	return func(seq iter.Seq[int]) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for p := range seq {
+				if p > n || !yield(p) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Take passes the first n members.
+func Take(n int) Stage {
+	return func(seq iter.Seq[int]) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			if n <= 0 {
+				return
+			}
+			i := 0
+			for p := range seq {
+				if !yield(p) {
+					return
+				}
+				if i++; i == n {
+					return
+				}
+			}
+		}
+	}
+}
+
+// PrimeIndexed passes the members at prime positions (counting from 1), repeated k
+// times. Applied to All(), k=1 gives the super-primes (http://oeis.org/A006450) and k=2
+// the primes of prime-indexed index (http://oeis.org/A038580).
+func PrimeIndexed(k int) Stage {
+	return func(seq iter.Seq[int]) iter.Seq[int] {
+		for range k {
+			seq = primePositions(seq)
+		}
+		return seq
+	}
+}
+
+// primePositions passes the members at prime positions.
+func primePositions(seq iter.Seq[int]) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		i := uint64(0)
+		for p := range seq {
+			if i++; isPrime64(i) && !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+// Pairs passes the members p for which p+offset is also prime: the lesser twin, cousin
+// and sexy primes for offsets 2, 4 and 6 (http://oeis.org/A001359, A023200, A023201).
+func Pairs(offset int) Stage {
+	return Filter(func(p int) bool {
+		return p+offset >= 2 && isPrime64(uint64(p+offset))
+	})
+}
+
+// Isolated passes the members p for which neither p-2 nor p+2 is prime
+// (http://oeis.org/A007510).
+func Isolated(seq iter.Seq[int]) iter.Seq[int] {
+	return Filter(func(p int) bool {
+		return (p < 4 || !isPrime64(uint64(p-2))) && !isPrime64(uint64(p+2))
+	})(seq)
+}
+
+// Chen passes the members p for which p+2 is either prime or the product of two primes
+// (http://oeis.org/A109611). Chen Jingrun proved in 1973 that there are infinitely many.
+func Chen(seq iter.Seq[int]) iter.Seq[int] {
+	return Filter(func(p int) bool {
+		m := uint64(p + 2)
+		return isPrime64(m) || semiprime(m)
+	})(seq)
+}
+
+// semiprime reports whether m is the product of exactly two primes. Trial division to the
+// cube root finds the smaller factor of any m with three or more.
+func semiprime(m uint64) bool {
+	if m%2 == 0 {
+		return isPrime64(m / 2)
+	}
+	for d := uint64(3); d*d*d <= m; d += 2 {
+		if m%d == 0 {
+			return isPrime64(m / d)
+		}
+	}
+	return m > 1 && !isPrime64(m)
+}
+
+// Balanced passes the members that are the average of their neighbors in the stream.
+// Applied to All() these are the balanced primes (http://oeis.org/A006562).
+func Balanced(seq iter.Seq[int]) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		prev, cur, n := 0, 0, 0
+		for next := range seq {
+			if n++; n >= 3 && 2*cur == prev+next && !yield(cur) {
+				return
+			}
+			prev, cur = cur, next
+		}
+	}
+}
+
+// Good passes the nth member whose square exceeds the product of the members i before
+// and after it for every 1 <= i < n. Applied to All() these are the good primes
+// (http://oeis.org/A028388). Deciding the nth member may read to the (2n-1)th, all of
+// which are retained.
+func Good(seq iter.Seq[int]) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		next, stop := iter.Pull(seq)
+		defer stop()
+		var p []int // the members read so far, p[0] first
+
+		// read reads through p[i], reporting false if the stream ends first
+		read := func(i int) bool {
+			for len(p) <= i {
+				q, ok := next()
+				if !ok {
+					return false
+				}
+				p = append(p, q)
+			}
+			return true
+		}
+		for n := 1; read(n); n++ {
+			good := true
+			for i := 1; i <= n && good; i++ {
+				if !read(n + i) {
+					return // the stream ended before the question was settled
+				}
+				good = productLess(p[n-i], p[n+i], p[n], p[n])
+			}
+			if good && !yield(p[n]) {
+				return
+			}
+		}
+	}
+}
+
+// productLess reports whether a*b < c*d for nonnegative a, b, c and d, exactly.
+func productLess(a, b, c, d int) bool {
+	hi1, lo1 := bits.Mul64(uint64(a), uint64(b))
+	hi2, lo2 := bits.Mul64(uint64(c), uint64(d))
+	return hi1 < hi2 || hi1 == hi2 && lo1 < lo2
+}
+
+// Ramanujan transforms All() into the Ramanujan primes (http://oeis.org/A104272): R(n)
+// is the least number for which π(x)-π(x/2) >= n for all x >= R(n). As primes are read,
+// π(x)-π(x/2) rises at each prime and falls at each twice a prime; R(n) is the prime at
+// which it last rose to n. Since R(n) <= p(3n) (Laishram, 2010), R(n) is known once the
+// 3n-th prime has been read. Applied to any other stream, it ends at the first n for
+// which the count has not yet reached n, as the bound no longer holds.
+func Ramanujan(seq iter.Seq[int]) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		var primes []int // every prime read, whose doubles mark the falls
+		rose := []int{0} // rose[n] is the latest prime at which the count rose to n
+		count, falls, n := 0, 0, 1
+		for p := range seq {
+			for ; falls < len(primes) && 2*primes[falls] < p; falls++ {
+				count--
+			}
+			primes = append(primes, p)
+			if count++; count == len(rose) {
+				rose = append(rose, p)
+			} else {
+				rose[count] = p
+			}
+			for ; 3*n <= len(primes); n++ {
+				if n >= len(rose) || !yield(rose[n]) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package sieve
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+)
+
+var streamTests = []struct {
+	name  string
+	stage Stage
+	count int   // members <= 20000
+	first []int // the sequence's first terms from the OEIS
+}{
+	{"A006450", PrimeIndexed(1), 335, []int{3, 5, 11, 17, 31, 41, 59, 67, 83, 109, 127, 157, 179, 191, 211}},
+	{"A038580", PrimeIndexed(2), 67, []int{5, 11, 31, 59, 127, 179, 277, 331, 431, 599, 709, 919, 1063, 1153}},
+	{"A001359", Pairs(2), 342, []int{3, 5, 11, 17, 29, 41, 59, 71, 101, 107, 137, 149, 179, 191, 197}},
+	{"A023200", Pairs(4), 344, []int{3, 7, 13, 19, 37, 43, 67, 79, 97, 103, 109, 127, 163, 193, 223}},
+	{"A023201", Pairs(6), 693, []int{5, 7, 11, 13, 17, 23, 31, 37, 41, 47, 53, 61, 67, 73, 83}},
+	{"A006562", Balanced, 126, []int{5, 53, 157, 173, 211, 257, 263, 373, 563, 593, 607, 653, 733, 947, 977}},
+	{"A007510", Isolated, 1579, []int{2, 23, 37, 47, 53, 67, 79, 83, 89, 97, 113, 127, 131, 157, 163}},
+	{"A109611", Chen, 1112, []int{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 47, 53, 59, 67, 71, 83}},
+	{"A028388", Good, 257, []int{5, 11, 17, 29, 37, 41, 53, 59, 67, 71, 97, 101, 127, 149, 179, 191}},
+	{"A104272", Ramanujan, 1032, []int{2, 11, 17, 29, 41, 47, 59, 67, 71, 97, 101, 107, 127, 149, 151}},
+}
+
+// Are the built-in stages right, whether the sieve holds the stream or segments do?
+func TestStages(t *testing.T) {
+	for _, s := range []*Sieve{New(100000), New(100)} {
+		for i, a := range streamTests {
+			first := slices.Collect(Compose(s.All(), a.stage, Take(len(a.first))))
+			if !slices.Equal(first, a.first) {
+				t.Errorf("#%d, %s: New(%d) gives %v; want %v", i, a.name, s.Size(), first, a.first)
+			}
+			if count := len(slices.Collect(Compose(s.All(), a.stage, Below(20000)))); count != a.count {
+				t.Errorf("#%d, %s: New(%d) gives %d terms <= 20000; want %d", i, a.name, s.Size(), count, a.count)
+			}
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	s := New(1000)
+	odd := Filter(func(p int) bool { return p%2 == 1 })
+	if got := slices.Collect(Compose(s.All(), odd, Take(5))); !slices.Equal(got, []int{3, 5, 7, 11, 13}) {
+		t.Errorf("odd primes %v", got)
+	}
+	if got := slices.Collect(Compose(s.All(), Take(0))); len(got) != 0 {
+		t.Errorf("Take(0) gives %v", got)
+	}
+	if got := slices.Collect(Compose(s.All(), Below(1))); len(got) != 0 {
+		t.Errorf("Below(1) gives %v", got)
+	}
+
+	// a finite stream ends without settling its last members
+	finite := func(xs ...int) iter.Seq[int] { return slices.Values(xs) }
+	if got := slices.Collect(Good(finite(2, 3, 5, 7, 11, 13))); !slices.Equal(got, []int{5}) {
+		t.Errorf("Good on six primes gives %v", got)
+	}
+	if got := slices.Collect(Balanced(finite(3, 5, 7, 9, 11))); !slices.Equal(got, []int{5, 7, 9}) {
+		t.Errorf("Balanced on odd numbers gives %v", got)
+	}
+
+	// Ramanujan on streams other than All() ends rather than reading past what it knows
+	if got := slices.Collect(Ramanujan(finite(2, 5, 11, 23, 47, 97, 197, 397, 797))); !slices.Equal(got, []int{11}) {
+		t.Errorf("Ramanujan on a sparse stream gives %v", got)
+	}
+	quarter := Filter(func(p int) bool { return p%4 == 1 })
+	for p := range Compose(s.All(), quarter, Below(20000), Ramanujan) {
+		if p%4 != 1 {
+			t.Errorf("Ramanujan on primes 1 mod 4 gives %d", p)
+		}
+	}
+}
+
+// Do streams reach far beyond a small sieve?
+func TestAllBeyond(t *testing.T) {
+	s := New(100)
+	n := 0
+	for p := range s.All() {
+		if n++; n == 10000 {
+			if p != 104729 {
+				t.Errorf("10000th prime is %d; want %d", p, 104729)
+			}
+			break
+		}
+	}
+}
+
+func ExampleCompose() {
+	// Find the first ten prime-indexed lesser twin primes.
+	s := New(10000)
+	fmt.Println(slices.Collect(Compose(s.All(), Pairs(2), PrimeIndexed(1), Take(10))))
+	// Output:
+	// [5 11 29 59 137 179 239 281 431 641]
+}
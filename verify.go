@@ -0,0 +1,149 @@
+package sieve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// GoldbachRecord marks an even number whose least Goldbach prime exceeds that of every
+// smaller even number examined, as tabulated by Oliveira e Silva, Herzog and Pardi.
+type GoldbachRecord struct {
+	N uint64 `json:"n"` // the even number
+	P uint64 `json:"p"` // the least prime p for which N-p is also prime
+}
+
+// GoldbachCheckpoint is the resumable state of a verification, saved as JSON.
+type GoldbachCheckpoint struct {
+	Lo      uint64           `json:"lo"`      // first even number of the run
+	Hi      uint64           `json:"hi"`      // last number of the run
+	Next    uint64           `json:"next"`    // first even number not yet verified
+	Done    bool             `json:"done"`    // the whole range is verified
+	Records []GoldbachRecord `json:"records"` // records so far
+}
+
+// goldbachWindow is the number of even numbers verified between checkpoints.
+const goldbachWindow = 1 << 20
+
+// goldbachReach bounds the primes p tried from the sieved window; n-p for larger p is
+// tested individually. The least Goldbach prime for n < 4*10^18 is at most 9781.
+const goldbachReach = 1 << 14
+
+// VerifyGoldbach proves that every even n in [lo, hi], n >= 4, is the sum of two primes,
+// returning the record least primes in the manner of Oliveira e Silva's verification. A
+// window of odd numbers reaching goldbachReach below the even numbers under test is
+// sieved, and for each n the primes p = 3, 5, 7, ... are tried until n-p is found prime
+// in the window. The sieve should reach sqrt(hi) for speed; it need not for correctness.
+//
+// Long runs can be interrupted and resumed: when checkpoint names a file, progress is
+// saved there after each window of even numbers, and a run over the same range resumes
+// from the saved state. An error is returned if the checkpoint cannot be used or if an
+// even number without a partition is found.
+func (sieve *Sieve) VerifyGoldbach(lo, hi uint64, checkpoint string) ([]GoldbachRecord, error) {
+	if lo == math.MaxUint64 {
+		return nil, nil // no even number is as large
+	}
+	lo = max(lo+lo&1, 4) // first even number >= 4
+	state := GoldbachCheckpoint{Lo: lo, Hi: hi, Next: lo}
+	if checkpoint != "" {
+		if err := state.load(checkpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	var best uint64
+	if len(state.Records) > 0 {
+		best = state.Records[len(state.Records)-1].P
+	}
+	for !state.Done && state.Next <= hi {
+		a := state.Next
+		b := a + 2*(goldbachWindow-1)
+		if b < a || b > hi {
+			b = hi
+		}
+		base := uint64(1)
+		if a > goldbachReach {
+			base = a - goldbachReach
+		}
+		seg := sieve.newSegment(base, b, 0)
+
+		for n := a; n <= b && n >= a; n += 2 { // n >= a detects overflow
+			p := sieve.leastGoldbach(n, seg)
+			if p == 0 {
+				return state.Records, fmt.Errorf("sieve: Goldbach's conjecture fails for %d", n)
+			}
+			if p > best {
+				best = p
+				state.Records = append(state.Records, GoldbachRecord{n, p})
+			}
+		}
+		if hi-b < 2 {
+			state.Done = true
+		} else {
+			state.Next = b + 2
+		}
+		if checkpoint != "" {
+			if err := state.save(checkpoint); err != nil {
+				return state.Records, err
+			}
+		}
+	}
+	return state.Records, nil
+}
+
+// leastGoldbach returns the least prime p for which even n-p is prime, or 0 if none.
+func (sieve *Sieve) leastGoldbach(n uint64, seg *segment) uint64 {
+	switch {
+	case n == 4:
+		return 2
+	case n < 4:
+		return 0
+	}
+	for p := uint64(3); p <= n/2; p = sieve.NextPrime(p) {
+		m := n - p
+		if p <= goldbachReach && m >= seg.lo {
+			if seg.prime(m) {
+				return p
+			}
+		} else if sieve.isPrime(m) {
+			return p
+		}
+	}
+	return 0
+}
+
+// load restores saved state for the same range, if any has been saved.
+func (state *GoldbachCheckpoint) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // a fresh run
+	}
+	if err != nil {
+		return err
+	}
+	var saved GoldbachCheckpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("sieve: checkpoint %s: %v", path, err)
+	}
+	if saved.Lo != state.Lo || saved.Hi != state.Hi {
+		return fmt.Errorf("sieve: checkpoint %s is for [%d, %d], not [%d, %d]", path, saved.Lo, saved.Hi, state.Lo, state.Hi)
+	}
+	*state = saved
+	return nil
+}
+
+// save writes the state to a temporary file and renames it into place, so that an
+// interrupted save leaves the previous checkpoint intact.
+func (state *GoldbachCheckpoint) save(path string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
@@ -0,0 +1,102 @@
+package sieve
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Record least Goldbach primes (http://oeis.org/A025018 and http://oeis.org/A025019)
+var goldbachRecords = []GoldbachRecord{
+	{4, 2}, {6, 3}, {12, 5}, {30, 7}, {98, 19}, {220, 23}, {308, 31}, {556, 47}, {992, 73},
+	{2642, 103}, {5372, 139}, {7426, 173}, {43532, 211}, {54244, 233}, {63274, 293},
+	{113672, 313}, {128168, 331}, {194428, 359}, {194470, 383}, {413572, 389},
+	{503222, 523}, {1077422, 601},
+}
+
+func TestVerifyGoldbach(t *testing.T) {
+	for _, a := range []struct{ size, hi int }{{2000, 2000000}, {100, 200000}} {
+		var want []GoldbachRecord
+		for _, r := range goldbachRecords {
+			if r.N <= uint64(a.hi) {
+				want = append(want, r)
+			}
+		}
+		records, err := New(a.size).VerifyGoldbach(0, uint64(a.hi), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(records, want) {
+			t.Errorf("New(%d).VerifyGoldbach(0, %d) = %v; want %v", a.size, a.hi, records, want)
+		}
+	}
+}
+
+// Are records relative to the start of a range far beyond the sieve?
+func TestVerifyGoldbachRange(t *testing.T) {
+	want := []GoldbachRecord{
+		{1000000000000, 11}, {1000000000002, 13}, {1000000000004, 43}, {1000000000010, 73},
+		{1000000000016, 79}, {1000000000024, 167}, {1000000000054, 191}, {1000000000154, 193},
+		{1000000000388, 199}, {1000000000396, 227}, {1000000000424, 463}, {1000000001716, 503},
+		{1000000001836, 593}, {1000000005554, 601}, {1000000008152, 631}, {1000000009348, 701},
+		{1000000011316, 827}, {1000000016972, 919},
+	}
+	records, err := New(1000000).VerifyGoldbach(1000000000000, 1000000020001, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(records, want) {
+		t.Errorf("VerifyGoldbach(10^12, 10^12+20001) = %v; want %v", records, want)
+	}
+	if records, err := New(1000).VerifyGoldbach(math.MaxUint64, math.MaxUint64, ""); err != nil || len(records) != 0 {
+		t.Errorf("VerifyGoldbach(2^64-1, 2^64-1) = %v, %v", records, err)
+	}
+}
+
+// Does a run resume from its checkpoint and refuse another range's checkpoint?
+func TestVerifyGoldbachCheckpoint(t *testing.T) {
+	s := New(2000)
+	path := filepath.Join(t.TempDir(), "goldbach.json")
+
+	// pretend an earlier run stopped at 200000
+	var partial []GoldbachRecord
+	for _, r := range goldbachRecords {
+		if r.N < 200000 {
+			partial = append(partial, r)
+		}
+	}
+	data, _ := json.Marshal(GoldbachCheckpoint{Lo: 4, Hi: 2000000, Next: 200000, Records: partial})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := s.VerifyGoldbach(4, 2000000, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(records, goldbachRecords) {
+		t.Errorf("resumed run = %v; want %v", records, goldbachRecords)
+	}
+
+	var saved GoldbachCheckpoint
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Done || !slices.Equal(saved.Records, goldbachRecords) {
+		t.Errorf("final checkpoint = %+v", saved)
+	}
+
+	// a finished run returns its records without further work
+	if records, err := s.VerifyGoldbach(4, 2000000, path); err != nil || !slices.Equal(records, goldbachRecords) {
+		t.Errorf("finished run = %v, %v", records, err)
+	}
+	if _, err := s.VerifyGoldbach(4, 1000000, path); err == nil {
+		t.Errorf("checkpoint for another range was accepted")
+	}
+}